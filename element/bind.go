@@ -1,6 +1,11 @@
 package element
 
-import "fyne.io/fyne/v2/data/binding"
+import (
+	"context"
+
+	"fyne.io/fyne/v2/data/binding"
+	"github.com/shofster/common/misc"
+)
 
 /*
 
@@ -16,6 +21,9 @@ import "fyne.io/fyne/v2/data/binding"
 */
 /*
   Description: Binding getter and setter that drop error.
+
+  BindSetting... make a binding of a misc.Settings key. Setting either
+    one sets the other, until ctx ends or the settings are closed.
 */
 
 // StringGetter gets bound string. swallows error.
@@ -56,3 +64,62 @@ func IntGetter(bi binding.Int) int {
 func IntSetter(s int, bi binding.Int) {
 	_ = bi.Set(s)
 }
+
+// BindSettingString binds the settings key as a string.
+//goland:noinspection GoUnusedExportedFunction
+func BindSettingString(ctx context.Context, s *misc.Settings, key string) binding.String {
+	b := binding.NewString()
+	bindSetting[string](ctx, b, s, key, s.String)
+	return b
+}
+
+// BindSettingBool binds the settings key as a bool.
+//goland:noinspection GoUnusedExportedFunction
+func BindSettingBool(ctx context.Context, s *misc.Settings, key string) binding.Bool {
+	b := binding.NewBool()
+	bindSetting[bool](ctx, b, s, key, s.Bool)
+	return b
+}
+
+// BindSettingInt binds the settings key as an int.
+//goland:noinspection GoUnusedExportedFunction
+func BindSettingInt(ctx context.Context, s *misc.Settings, key string) binding.Int {
+	b := binding.NewInt()
+	bindSetting[int](ctx, b, s, key, s.Int)
+	return b
+}
+
+// BindSettingFloat binds the settings key as a float64.
+//goland:noinspection GoUnusedExportedFunction
+func BindSettingFloat(ctx context.Context, s *misc.Settings, key string) binding.Float {
+	b := binding.NewFloat()
+	bindSetting[float64](ctx, b, s, key, s.Float)
+	return b
+}
+
+// settingBinding is what the fyne bindings of a type have in common.
+type settingBinding[T comparable] interface {
+	binding.DataItem
+	Get() (T, error)
+	Set(T) error
+}
+
+// bindSetting keeps b and key the same until ctx ends.
+func bindSetting[T comparable](ctx context.Context, b settingBinding[T], s *misc.Settings, key string, get func(string) T) {
+	_ = b.Set(get(key))
+	listener := binding.NewDataListener(func() {
+		if v, err := b.Get(); err == nil && v != get(key) {
+			_ = s.Set(key, v)
+		}
+	})
+	b.AddListener(listener)
+	changes, _ := s.Publisher().Subscribe(ctx, key, misc.SubscribeOptions{Buffer: 1})
+	go func() {
+		defer b.RemoveListener(listener)
+		for range changes {
+			if v, err := b.Get(); err != nil || v != get(key) {
+				_ = b.Set(get(key))
+			}
+		}
+	}()
+}
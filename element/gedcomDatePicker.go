@@ -0,0 +1,433 @@
+package element
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/shofster/common/gedcom"
+)
+
+/*
+
+  File:    gedcomDatePicker.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: Widget to pick a GEDCOM date.
+
+  A qualifier (exact, ABT, CAL, EST, BEF, AFT, BET/AND, FROM/TO) and a
+    calendar (Gregorian, Julian, Hebrew, French Republican) are chosen
+    from Selects. Each date has a day, a month and a year; the button
+    beside them pops up a calendar of the month to pick the day from.
+    The second date shows for BET/AND and FROM/TO only (for FROM/TO
+    either one may be left empty).
+  The preview below shows the GEDCOM string, or what is wrong with it.
+    The fields are never changed behind the user's back.
+  Text (and a bound binding.String) is only set from a valid date.
+*/
+
+var _ fyne.Widget = (*GedcomDatePicker)(nil)
+
+// the qualifier Select options
+const (
+	pickExact   = "exact"
+	pickBetween = "BET/AND"
+	pickFromTo  = "FROM/TO"
+)
+
+var pickQualifiers = []string{pickExact, "ABT", "CAL", "EST", "BEF", "AFT", pickBetween, pickFromTo}
+
+var pickCalendars = []string{"Gregorian", "Julian", "Hebrew", "French Republican"}
+
+// GedcomDatePicker is a widget to pick a GEDCOM date.
+type GedcomDatePicker struct {
+	widget.BaseWidget
+	OnChanged func(string) // called with each new valid date
+
+	text      string
+	err       error
+	qualifier *widget.Select
+	calendar  *widget.Select
+	first     *pickerDate
+	second    *pickerDate
+	and       *widget.Label
+	preview   *widget.Label
+	content   *fyne.Container
+	data      binding.String
+	listener  binding.DataListener
+	updating  bool
+}
+
+// pickerDate are the fields of one date.
+type pickerDate struct {
+	day   *widget.Entry
+	month *widget.Select
+	year  *widget.Entry
+	pick  *widget.Button
+	box   *fyne.Container
+}
+
+// NewGedcomDatePicker creates an empty picker.
+//goland:noinspection GoUnusedExportedFunction
+func NewGedcomDatePicker() *GedcomDatePicker {
+	p := &GedcomDatePicker{}
+	p.ExtendBaseWidget(p)
+	p.qualifier = widget.NewSelect(pickQualifiers, func(string) {
+		p.showSecond()
+		p.update()
+	})
+	p.calendar = widget.NewSelect(pickCalendars, func(string) {
+		p.setMonths()
+		p.update()
+	})
+	p.first = p.newPickerDate()
+	p.second = p.newPickerDate()
+	p.and = widget.NewLabel("AND")
+	p.preview = widget.NewLabel("")
+	p.preview.Wrapping = fyne.TextWrapWord
+	p.content = container.NewVBox(
+		container.NewGridWithColumns(2, p.qualifier, p.calendar),
+		p.first.box,
+		p.and,
+		p.second.box,
+		p.preview,
+	)
+	p.qualifier.SetSelected(pickExact)
+	p.calendar.SetSelected(pickCalendars[gedcom.Gregorian])
+	return p
+}
+
+// NewGedcomDatePickerWithData creates a picker bound to data.
+//goland:noinspection GoUnusedExportedFunction
+func NewGedcomDatePickerWithData(data binding.String) *GedcomDatePicker {
+	p := NewGedcomDatePicker()
+	p.Bind(data)
+	return p
+}
+
+// CreateRenderer is a private method to Fyne which links this widget to its renderer
+func (p *GedcomDatePicker) CreateRenderer() fyne.WidgetRenderer {
+	p.ExtendBaseWidget(p)
+	return widget.NewSimpleRenderer(p.content)
+}
+
+// Bind the picker to data. The picker shows data as it changes, and sets it to each valid date.
+func (p *GedcomDatePicker) Bind(data binding.String) {
+	p.Unbind()
+	p.data = data
+	p.listener = binding.NewDataListener(func() {
+		s, err := data.Get()
+		if err != nil || s == p.text {
+			return
+		}
+		_ = p.SetText(s)
+	})
+	data.AddListener(p.listener)
+}
+
+// Unbind from the bound data (if any).
+func (p *GedcomDatePicker) Unbind() {
+	if p.data != nil {
+		p.data.RemoveListener(p.listener)
+	}
+	p.data = nil
+	p.listener = nil
+}
+
+// Text is the last valid date, as a GEDCOM string ("" if none).
+func (p *GedcomDatePicker) Text() string {
+	return p.text
+}
+
+// Validate reports what is wrong with the date in the fields. nil if valid (or empty).
+func (p *GedcomDatePicker) Validate() error {
+	return p.err
+}
+
+// Date parses Text.
+func (p *GedcomDatePicker) Date() (gedcom.GedcomDate, error) {
+	return gedcom.ParseDate(p.text)
+}
+
+// SetText fills the fields from a GEDCOM date string. "" clears them.
+func (p *GedcomDatePicker) SetText(s string) error {
+	if strings.TrimSpace(s) == "" {
+		p.SetDate(gedcom.GedcomDate{})
+		return nil
+	}
+	g, err := gedcom.ParseDate(s)
+	if err != nil {
+		return err
+	}
+	p.SetDate(g)
+	return nil
+}
+
+// SetDate fills the fields from a date. The phrase of INT and phrase only dates is not shown.
+func (p *GedcomDatePicker) SetDate(g gedcom.GedcomDate) {
+	p.updating = true
+	q := pickExact
+	switch g.Qualifier {
+	case gedcom.About, gedcom.Calculated, gedcom.Estimated, gedcom.Before, gedcom.After:
+		q = g.Qualifier.String()
+	case gedcom.Between:
+		q = pickBetween
+	case gedcom.From, gedcom.To, gedcom.FromTo:
+		q = pickFromTo
+	}
+	p.qualifier.SetSelected(q)
+	p.calendar.SetSelected(pickCalendars[g.Date.Calendar])
+	first, second := g.Date, g.Date2
+	if g.Qualifier == gedcom.To {
+		first, second = gedcom.CalendarDate{}, g.Date
+	}
+	p.first.set(first)
+	p.second.set(second)
+	p.updating = false
+	p.update()
+}
+
+func (p *GedcomDatePicker) newPickerDate() *pickerDate {
+	d := &pickerDate{}
+	d.day = widget.NewEntry()
+	d.day.SetPlaceHolder("Day")
+	d.day.OnChanged = func(string) { p.update() }
+	d.month = widget.NewSelect(nil, func(string) { p.update() })
+	d.month.PlaceHolder = "Month"
+	d.year = widget.NewEntry()
+	d.year.SetPlaceHolder("Year")
+	d.year.OnChanged = func(string) { p.update() }
+	d.pick = widget.NewButtonWithIcon("", theme.MenuDropDownIcon(), func() {
+		p.showCalendar(d)
+	})
+	d.box = container.NewBorder(nil, nil, nil, d.pick, container.NewGridWithColumns(3, d.day, d.month, d.year))
+	return d
+}
+
+// set the fields of a date.
+func (d *pickerDate) set(c gedcom.CalendarDate) {
+	if c.IsZero() {
+		d.day.SetText("")
+		d.month.ClearSelected()
+		d.year.SetText("")
+		return
+	}
+	if c.Day > 0 {
+		d.day.SetText(strconv.Itoa(c.Day))
+	} else {
+		d.day.SetText("")
+	}
+	if months := c.Calendar.Months(); c.Month > 0 && c.Month <= len(months) {
+		d.month.SetSelected(months[c.Month-1])
+	} else {
+		d.month.ClearSelected()
+	}
+	d.year.SetText(c.YearString())
+}
+
+// empty reports whether no field is filled.
+func (d *pickerDate) empty() bool {
+	return strings.TrimSpace(d.day.Text) == "" && d.month.Selected == "" && strings.TrimSpace(d.year.Text) == ""
+}
+
+// gedcom is the date of the fields, "1 JAN 1900".
+func (d *pickerDate) gedcom(c gedcom.Calendar) string {
+	parts := make([]string, 0, 4)
+	if c != gedcom.Gregorian {
+		parts = append(parts, c.Escape())
+	}
+	for _, s := range []string{d.day.Text, d.month.Selected, d.year.Text} {
+		if s = strings.TrimSpace(s); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func (p *GedcomDatePicker) selectedCalendar() gedcom.Calendar {
+	for i, c := range pickCalendars {
+		if c == p.calendar.Selected {
+			return gedcom.Calendar(i)
+		}
+	}
+	return gedcom.Gregorian
+}
+
+// setMonths offers the months of the calendar, keeping the month selected if it is one.
+func (p *GedcomDatePicker) setMonths() {
+	months := append([]string{""}, p.selectedCalendar().Months()...)
+	for _, d := range []*pickerDate{p.first, p.second} {
+		selected := d.month.Selected
+		d.month.Options = months
+		d.month.ClearSelected()
+		for _, m := range months[1:] {
+			if m == selected {
+				d.month.SetSelected(m)
+			}
+		}
+		d.month.Refresh()
+	}
+}
+
+func (p *GedcomDatePicker) showSecond() {
+	switch p.qualifier.Selected {
+	case pickBetween:
+		p.and.SetText("AND")
+	case pickFromTo:
+		p.and.SetText("TO")
+	default:
+		p.and.Hide()
+		p.second.box.Hide()
+		return
+	}
+	p.and.Show()
+	p.second.box.Show()
+}
+
+// gedcomString is the GEDCOM string of the fields.
+func (p *GedcomDatePicker) gedcomString() (string, error) {
+	c := p.selectedCalendar()
+	first, second := p.first.gedcom(c), p.second.gedcom(c)
+	switch q := p.qualifier.Selected; {
+	case p.first.empty() && (q != pickFromTo || p.second.empty()):
+		return "", nil
+	case q == pickBetween:
+		if p.second.empty() {
+			return "", errors.New("BET needs the AND date")
+		}
+		return fmt.Sprintf("BET %s AND %s", first, second), nil
+	case q == pickFromTo && p.first.empty():
+		return "TO " + second, nil
+	case q == pickFromTo && p.second.empty():
+		return "FROM " + first, nil
+	case q == pickFromTo:
+		return fmt.Sprintf("FROM %s TO %s", first, second), nil
+	case q == pickExact || q == "":
+		return first, nil
+	}
+	return p.qualifier.Selected + " " + first, nil
+}
+
+// update the preview, and Text if the date is valid.
+func (p *GedcomDatePicker) update() {
+	if p.updating || p.preview == nil {
+		return
+	}
+	s, err := p.gedcomString()
+	if err == nil && s != "" {
+		var g gedcom.GedcomDate
+		if g, err = gedcom.ParseDate(s); err == nil {
+			s = g.String()
+		}
+	}
+	p.err = err
+	if err != nil {
+		p.preview.SetText(err.Error())
+		return
+	}
+	p.preview.SetText(s)
+	if s == p.text {
+		return
+	}
+	p.text = s
+	if p.data != nil {
+		_ = p.data.Set(s)
+	}
+	if p.OnChanged != nil {
+		p.OnChanged(s)
+	}
+}
+
+// showCalendar pops up the month of d (or this month) to pick a day from.
+func (p *GedcomDatePicker) showCalendar(d *pickerDate) {
+	can := fyne.CurrentApp().Driver().CanvasForObject(p)
+	if can == nil {
+		return
+	}
+	c := p.selectedCalendar()
+	year, month, _ := c.Date(gedcom.Gregorian.JDN(time.Now().Year(), int(time.Now().Month()), time.Now().Day()))
+	if y, err := strconv.Atoi(strings.TrimSpace(d.year.Text)); err == nil && y > 0 {
+		year = y
+		month = 1
+	}
+	if m := c.Month(d.month.Selected); m > 0 {
+		month = m
+	}
+	title := widget.NewLabel("")
+	title.Alignment = fyne.TextAlignCenter
+	columns := 7 // weeks
+	if c == gedcom.French {
+		columns = 10 // décades
+	}
+	days := container.NewGridWithColumns(columns)
+	var pop *widget.PopUp
+	var show func()
+	last := 12
+	if c == gedcom.Hebrew || c == gedcom.French {
+		last = 13 // Hebrew ADS is only in a leap year
+	}
+	step := func(delta int) {
+		for {
+			month += delta
+			if month < 1 {
+				year, month = year-1, last
+			} else if month > last {
+				year, month = year+1, 1
+			}
+			if c.DaysInMonth(year, month) > 0 {
+				break
+			}
+		}
+		show()
+	}
+	show = func() {
+		title.SetText(fmt.Sprintf("%s %d", c.Months()[month-1], year))
+		objects := make([]fyne.CanvasObject, 0, 42)
+		if columns == 7 {
+			for i := 0; i < (c.JDN(year, month, 1)+1)%7; i++ { // JDN 0 was a Monday
+				objects = append(objects, layout.NewSpacer())
+			}
+		}
+		for day := 1; day <= c.DaysInMonth(year, month); day++ {
+			day := day
+			objects = append(objects, widget.NewButton(strconv.Itoa(day), func() {
+				pop.Hide()
+				p.updating = true
+				d.day.SetText(strconv.Itoa(day))
+				d.month.SetSelected(c.Months()[month-1])
+				d.year.SetText(strconv.Itoa(year))
+				p.updating = false
+				p.update()
+			}))
+		}
+		days.Objects = objects
+		days.Refresh()
+	}
+	if c.DaysInMonth(year, month) == 0 {
+		month = 1
+	}
+	header := container.NewBorder(nil, nil,
+		widget.NewButtonWithIcon("", theme.NavigateBackIcon(), func() { step(-1) }),
+		widget.NewButtonWithIcon("", theme.NavigateNextIcon(), func() { step(1) }),
+		title)
+	show()
+	pop = widget.NewPopUp(container.NewVBox(header, days), can)
+	pos := fyne.CurrentApp().Driver().AbsolutePositionForObject(d.pick)
+	pop.ShowAtPosition(pos.Add(fyne.NewPos(0, d.pick.Size().Height)))
+}
@@ -0,0 +1,165 @@
+package element
+
+import (
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/test"
+	"testing"
+)
+
+/*
+
+  File:    gedcomDatePicker_test.go
+  Author:  Bob Shofner
+
+*/
+
+// fields are what a user fills in: day, month and year of each date.
+type fields struct {
+	qualifier, calendar string
+	first, second       [3]string
+}
+
+func (f fields) fill(p *GedcomDatePicker) {
+	p.qualifier.SetSelected(f.qualifier)
+	if f.calendar != "" {
+		p.calendar.SetSelected(f.calendar)
+	}
+	for i, d := range []*pickerDate{p.first, p.second} {
+		v := [][3]string{f.first, f.second}[i]
+		d.day.SetText(v[0])
+		if v[1] == "" {
+			d.month.ClearSelected()
+		} else {
+			d.month.SetSelected(v[1])
+		}
+		d.year.SetText(v[2])
+	}
+}
+
+func TestGedcomDatePickerString(t *testing.T) {
+	test.NewApp()
+	var tests = []struct {
+		name   string
+		fields fields
+		want   string // Text, when valid
+		err    bool
+	}{
+		{"empty", fields{qualifier: pickExact}, "", false},
+		{"exact", fields{qualifier: pickExact, first: [3]string{"1", "JAN", "1900"}}, "1 JAN 1900", false},
+		{"year", fields{qualifier: "ABT", first: [3]string{"", "", "1900"}}, "ABT 1900", false},
+		{"BET", fields{qualifier: pickBetween, first: [3]string{"", "", "1900"}, second: [3]string{"", "", "1910"}},
+			"BET 1900 AND 1910", false},
+		{"BET without AND", fields{qualifier: pickBetween, first: [3]string{"", "", "1900"}}, "", true},
+		{"FROM only", fields{qualifier: pickFromTo, first: [3]string{"", "MAR", "1900"}}, "FROM MAR 1900", false},
+		{"TO only", fields{qualifier: pickFromTo, second: [3]string{"", "", "1910"}}, "TO 1910", false},
+		{"FROM TO", fields{qualifier: pickFromTo, first: [3]string{"", "", "1900"}, second: [3]string{"", "", "1910"}},
+			"FROM 1900 TO 1910", false},
+		{"Julian", fields{qualifier: pickExact, calendar: "Julian", first: [3]string{"11", "FEB", "1731/32"}},
+			"@#DJULIAN@ 11 FEB 1731/32", false},
+		{"Hebrew", fields{qualifier: "BEF", calendar: "Hebrew", first: [3]string{"1", "TSH", "5784"}},
+			"BEF @#DHEBREW@ 1 TSH 5784", false},
+		{"invalid day", fields{qualifier: pickExact, first: [3]string{"30", "FEB", "1900"}}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewGedcomDatePicker()
+			tt.fields.fill(p)
+			if err := p.Validate(); (err != nil) != tt.err {
+				t.Errorf("Validate = %v; want an error %t", err, tt.err)
+			}
+			if !tt.err && p.Text() != tt.want { // else Text is the last valid date while typing
+				t.Errorf("Expected %q: got %q", tt.want, p.Text())
+			}
+		})
+	}
+}
+
+func TestGedcomDatePickerSetText(t *testing.T) {
+	test.NewApp()
+	var tests = []struct {
+		date          string
+		qualifier     string
+		first, second [3]string
+	}{
+		{"1 JAN 1900", pickExact, [3]string{"1", "JAN", "1900"}, [3]string{}},
+		{"ABT 1900", "ABT", [3]string{"", "", "1900"}, [3]string{}},
+		{"BET 1900 AND 1910", pickBetween, [3]string{"", "", "1900"}, [3]string{"", "", "1910"}},
+		{"FROM MAR 1900", pickFromTo, [3]string{"", "MAR", "1900"}, [3]string{}},
+		{"TO 1910", pickFromTo, [3]string{}, [3]string{"", "", "1910"}},
+		{"FROM 1900 TO 1910", pickFromTo, [3]string{"", "", "1900"}, [3]string{"", "", "1910"}},
+		{"@#DFRENCH R@ 18 BRUM 8", pickExact, [3]string{"18", "BRUM", "8"}, [3]string{}},
+		{"AFT 44 B.C.", "AFT", [3]string{"", "", "44 B.C."}, [3]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.date, func(t *testing.T) {
+			p := NewGedcomDatePicker()
+			if err := p.SetText(tt.date); err != nil {
+				t.Fatal(err)
+			}
+			if p.Text() != tt.date || p.Validate() != nil {
+				t.Errorf("round trip: %q (%v)", p.Text(), p.Validate())
+			}
+			if p.qualifier.Selected != tt.qualifier {
+				t.Errorf("qualifier %q; want %q", p.qualifier.Selected, tt.qualifier)
+			}
+			for i, d := range []*pickerDate{p.first, p.second} {
+				want := [][3]string{tt.first, tt.second}[i]
+				if got := [3]string{d.day.Text, d.month.Selected, d.year.Text}; got != want {
+					t.Errorf("date %d fields %q; want %q", i+1, got, want)
+				}
+			}
+		})
+	}
+	p := NewGedcomDatePicker()
+	if p.SetText("1 DUNNO 1900") == nil {
+		t.Error("SetText of an invalid date: want an error")
+	}
+	_ = p.SetText("1900")
+	if _ = p.SetText(""); p.Text() != "" || !p.first.empty() {
+		t.Errorf("SetText empty: %q", p.Text())
+	}
+}
+
+func TestGedcomDatePickerBind(t *testing.T) {
+	test.NewApp()
+	data := binding.NewString()
+	p := NewGedcomDatePickerWithData(data)
+	changes := 0
+	p.OnChanged = func(string) { changes++ }
+	// flush waits for the listeners queued so far; they run on fyne's binding goroutine.
+	flush := func() {
+		done := make(chan struct{})
+		binding.NewBool().AddListener(binding.NewDataListener(func() { close(done) }))
+		<-done
+	}
+	flush()
+
+	_ = data.Set("ABT 1900") // the data shows in the fields
+	flush()
+	if p.Text() != "ABT 1900" || p.qualifier.Selected != "ABT" || p.first.year.Text != "1900" {
+		t.Errorf("fields %q: %s %s", p.Text(), p.qualifier.Selected, p.first.year.Text)
+	}
+
+	p.first.year.SetText("1910") // the fields set the data
+	flush()
+	if s, _ := data.Get(); s != "ABT 1910" || p.Text() != "ABT 1910" {
+		t.Errorf("data %q; want ABT 1910", s)
+	}
+
+	p.first.day.SetText("x") // an invalid date leaves the data alone
+	flush()
+	if s, _ := data.Get(); s != "ABT 1910" || p.Validate() == nil {
+		t.Errorf("invalid date: data %q, Validate %v", s, p.Validate())
+	}
+	if p.first.day.Text != "x" {
+		t.Error("the fields were changed behind the user's back")
+	}
+
+	before := changes
+	p.Unbind()
+	_ = data.Set("1800")
+	flush()
+	if p.Text() != "ABT 1910" || changes != before {
+		t.Errorf("after Unbind: %q, %d changes", p.Text(), changes-before)
+	}
+}
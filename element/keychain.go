@@ -1,7 +1,8 @@
 package element
 
 import (
-	"sync"
+	"context"
+	"github.com/shofster/common/misc"
 )
 
 /*
@@ -18,84 +19,40 @@ import (
 */
 /*
   Description: Handle key events for the application.
+	A KeyChain is a misc.Publisher of KeyPressed. Other event types
+	wrap misc.Publisher the same way.
 */
+
 type keyChain struct {
-	lock        sync.Mutex
-	input       chan KeyPressed
-	subscribe   chan chan<- KeyPressed
-	unsubscribe chan chan<- KeyPressed
-	outputs     map[chan<- KeyPressed]bool
+	misc.Publisher[KeyPressed]
 }
 
 // The KeyChain interface describes the methods of KeyChain(s).
+// After Close every call returns misc.ErrPublisherClosed and
+// every registered channel is closed.
 type KeyChain interface {
 	// Register a new channel to receive broadcasts
-	Register(chan<- KeyPressed)
+	Register(chan<- KeyPressed) error
 	// Unregister a channel so that it no longer receives broadcasts.
-	Unregister(chan<- KeyPressed)
-	// Close this key chain
+	Unregister(chan<- KeyPressed) error
+	// Subscribe creates a channel receiving broadcasts until ctx ends or unsubscribe is called.
+	Subscribe(ctx context.Context) (ch <-chan KeyPressed, unsubscribe func())
+	// Close this key chain, delivering any pending keys first.
 	Close() error
-	// Submit a new object to all subscribers return false if input chan is full
-	Submit(KeyPressed) bool
-}
-
-// run - forever handling requests.
-func (k *keyChain) run() {
-	for {
-		select {
-		case m := <-k.input: // publish (from k.Submit(m interface{}))
-			for ch := range k.outputs {
-				ch <- m
-			}
-		case ch, ok := <-k.subscribe: // new subscriber
-			if ok {
-				k.outputs[ch] = true
-			} else {
-				return
-			}
-		case ch := <-k.unsubscribe: // quit subscribing
-			delete(k.outputs, ch)
-		}
-	}
+	// CloseContext closes this key chain. Pending keys are delivered or
+	// discarded per mode; when ctx ends first the rest are discarded and ctx.Err() returned.
+	CloseContext(ctx context.Context, mode misc.CloseMode) error
+	// Submit a new object to all subscribers. misc.ErrPublisherFull if input chan is full
+	Submit(KeyPressed) error
 }
 
 // NewKeyChain creates a publisher with the given channel buffer length.
 //goland:noinspection GoUnusedExportedFunction
 func NewKeyChain(buflen int) KeyChain {
-	k := &keyChain{
-		input:       make(chan KeyPressed, buflen),    // bi-directional
-		subscribe:   make(chan chan<- KeyPressed),     // send only
-		unsubscribe: make(chan chan<- KeyPressed),     // send only
-		outputs:     make(map[chan<- KeyPressed]bool), // send only
-	}
-	go k.run()
-	return k
-}
-func (k *keyChain) Close() error {
-	defer k.lock.Unlock()
-	k.lock.Lock()
-	close(k.subscribe)
-	close(k.unsubscribe)
-	return nil
-}
-func (k *keyChain) Register(ch chan<- KeyPressed) {
-	defer k.lock.Unlock()
-	k.lock.Lock()
-	k.subscribe <- ch
-}
-func (k *keyChain) Unregister(ch chan<- KeyPressed) {
-	defer k.lock.Unlock()
-	k.lock.Lock()
-	k.unsubscribe <- ch
+	return &keyChain{misc.NewPublisher[KeyPressed](buflen)}
 }
 
-// Submit attempts to submit an item to be key chained, returning
-// true if successful, else false.
-func (k *keyChain) Submit(m KeyPressed) bool {
-	select { // block until a case can run and buffer has room
-	case k.input <- m:
-		return true
-	default: // input channel is not ready. ignore
-		return false
-	}
+func (k *keyChain) Subscribe(ctx context.Context) (<-chan KeyPressed, func()) {
+	return k.Publisher.Subscribe(ctx, misc.AllTopics,
+		misc.SubscribeOptions{Buffer: 1, Policy: misc.Block})
 }
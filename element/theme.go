@@ -1,6 +1,7 @@
 package element
 
 import (
+	"context"
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/theme"
 	"image/color"
@@ -32,8 +33,7 @@ type ScsiTheme struct {
 //goland:noinspection GoUnusedExportedFunction
 func NewTheme(kc KeyChain) *ScsiTheme {
 	t := &ScsiTheme{}
-	kp := make(chan KeyPressed)
-	kc.Register(kp)
+	kp, _ := kc.Subscribe(context.Background())
 	go func() { // process key event
 		for p := range kp {
 			t.keyHandler(p)
@@ -0,0 +1,278 @@
+package fileutil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"github.com/shofster/common/misc"
+)
+
+/*
+
+  File:    convert.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: Normalize the encoding and line endings of a tree of text files.
+
+  Each file is read with utfutil (detecting its encoding), binaries are
+    skipped by content sniffing, and the text is written back in the
+    target encoding and line ending. A file is only rewritten if its
+    bytes change; the rewrite goes to a temporary file in the same
+    directory which is then renamed over the original.
+  With DryRun nothing is written; the report tells what would change.
+*/
+
+// LineEnding selects the line ending written by a conversion.
+type LineEnding int
+
+const (
+	KeepLineEnding LineEnding = iota
+	LF
+	CRLF
+)
+
+func (l LineEnding) String() string {
+	switch l {
+	case LF:
+		return "LF"
+	case CRLF:
+		return "CRLF"
+	}
+	return "keep"
+}
+
+// sniffLen is what http.DetectContentType looks at.
+const sniffLen = 512
+
+// TextConversion describes the target of ConvertTextTree.
+type TextConversion struct {
+	Encoding   misc.EncodingHint // target encoding. AUTO keeps each file's encoding (and BOM)
+	LineEnding LineEnding
+	BOM        bool     // write a BOM (UTF encodings only)
+	Replace    bool     // replace characters the target lacks, instead of failing the file
+	Ext        []string // only files with these extensions (".txt"). empty = all
+	Hidden     string   // skip files and directories matching this expression (see DefaultHiddenFiles)
+	DryRun     bool     // report only
+}
+
+// TextFileReport is what ConvertTextTree found (and did) for one file.
+type TextFileReport struct {
+	Path     string
+	Binary   bool // skipped
+	Encoding misc.EncodingHint
+	BOM      bool
+	LF       int // lines ending in LF only
+	CRLF     int
+	CR       int  // lines ending in CR only (classic Mac)
+	Changed  bool // rewritten, or would be with DryRun
+	Err      error
+}
+
+// Mixed reports whether the file uses more than one line ending.
+func (r TextFileReport) Mixed() bool {
+	n := 0
+	for _, c := range []int{r.LF, r.CRLF, r.CR} {
+		if c > 0 {
+			n++
+		}
+	}
+	return n > 1
+}
+
+func (r TextFileReport) String() string {
+	if r.Err != nil {
+		return fmt.Sprintf("%s: %v", r.Path, r.Err)
+	}
+	if r.Binary {
+		return fmt.Sprintf("%s: binary", r.Path)
+	}
+	bom := ""
+	if r.BOM {
+		bom = " BOM"
+	}
+	changed := ""
+	if r.Changed {
+		changed = " *"
+	}
+	return fmt.Sprintf("%s: %s%s LF %d CRLF %d CR %d%s",
+		r.Path, r.Encoding, bom, r.LF, r.CRLF, r.CR, changed)
+}
+
+// ConvertTextTree converts every text file under root. The error is for the walk
+// itself; a file that could not be converted has its own TextFileReport.Err.
+//goland:noinspection GoUnusedExportedFunction
+func ConvertTextTree(root string, conv TextConversion) ([]TextFileReport, error) {
+	var hidden *regexp.Regexp
+	if conv.Hidden != "" {
+		var err error
+		if hidden, err = regexp.Compile(conv.Hidden); err != nil {
+			return nil, err
+		}
+	}
+	reports := make([]TextFileReport, 0)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			reports = append(reports, TextFileReport{Path: path, Err: err})
+			return nil
+		}
+		if path != root && hidden != nil && hidden.MatchString(d.Name()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !wantExt(path, conv.Ext) {
+			return nil
+		}
+		reports = append(reports, ConvertTextFile(path, conv))
+		return nil
+	})
+	return reports, err
+}
+
+// ConvertTextFile converts one file.
+//goland:noinspection GoUnusedExportedFunction
+func ConvertTextFile(path string, conv TextConversion) (r TextFileReport) {
+	r.Path = path
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		r.Err = err
+		return
+	}
+	r.Encoding, r.BOM = misc.DetectEncoding(data)
+	if isBinary(data, r.Encoding) {
+		r.Binary = true
+		return
+	}
+	rd, _ := misc.NewReaderDetect(bytes.NewReader(data), r.Encoding)
+	text, err := ioutil.ReadAll(rd)
+	if err != nil {
+		r.Err = err
+		return
+	}
+	r.LF, r.CRLF, r.CR = countLineEndings(text)
+
+	target := conv.Encoding
+	opts := misc.WriteOptions{BOM: conv.BOM, Replace: conv.Replace}
+	if target == misc.AUTO {
+		target = r.Encoding
+		opts.BOM = r.BOM
+	}
+	if conv.LineEnding != KeepLineEnding {
+		text = toLF(text)
+		opts.CRLF = conv.LineEnding == CRLF
+	}
+	var buf bytes.Buffer
+	w := misc.NewWriter(&buf, target, opts)
+	_, err = w.Write(text)
+	if e := w.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		r.Err = errors.New(fmt.Sprintf("%s: %v", target, err))
+		return
+	}
+	r.Changed = !bytes.Equal(buf.Bytes(), data)
+	if r.Changed && !conv.DryRun {
+		r.Err = replaceFile(path, buf.Bytes())
+	}
+	return
+}
+
+func wantExt(path string, exts []string) bool {
+	if len(exts) == 0 {
+		return true
+	}
+	have := filepath.Ext(path)
+	for _, ext := range exts {
+		if strings.EqualFold(ext, have) {
+			return true
+		}
+	}
+	return false
+}
+
+// isBinary sniffs the start of the file. UTF-16/32 looks binary to
+// http.DetectContentType (all those zeros), so sniff it decoded.
+func isBinary(data []byte, d misc.EncodingHint) bool {
+	if len(data) > sniffLen {
+		data = data[:sniffLen]
+	}
+	switch d {
+	case misc.UTF16LE, misc.UTF16BE, misc.UTF32LE, misc.UTF32BE:
+		rd, _ := misc.NewReaderDetect(bytes.NewReader(data), d)
+		data, _ = ioutil.ReadAll(rd)
+	}
+	return !strings.HasPrefix(http.DetectContentType(data), "text/")
+}
+
+func countLineEndings(text []byte) (lf, crlf, cr int) {
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\n':
+			lf++
+		case '\r':
+			if i+1 < len(text) && text[i+1] == '\n' {
+				crlf++
+				i++
+			} else {
+				cr++
+			}
+		}
+	}
+	return
+}
+
+// toLF makes every line end in LF.
+func toLF(text []byte) []byte {
+	text = bytes.ReplaceAll(text, []byte("\r\n"), []byte("\n"))
+	return bytes.ReplaceAll(text, []byte("\r"), []byte("\n"))
+}
+
+// replaceFile writes data next to path, then renames it over path.
+func replaceFile(path string, data []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	if err == nil {
+		err = os.Chmod(tmp, info.Mode().Perm())
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+	}
+	return err
+}
@@ -0,0 +1,119 @@
+package fileutil
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"github.com/shofster/common/misc"
+)
+
+/*
+
+  File:    convert_test.go
+  Author:  Bob Shofner
+
+*/
+
+func TestConvertTextFile(t *testing.T) {
+	png := "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01"
+	tests := []struct {
+		name    string
+		data    string
+		conv    TextConversion
+		want    string // the file afterwards
+		binary  bool
+		changed bool
+		counts  [3]int // LF CRLF CR
+	}{
+		{"to LF", "a\r\nb\r\nc\r", TextConversion{LineEnding: LF}, "a\nb\nc\n", false, true, [3]int{0, 2, 1}},
+		{"to CRLF", "a\nb\n", TextConversion{LineEnding: CRLF}, "a\r\nb\r\n", false, true, [3]int{2, 0, 0}},
+		{"keep", "a\r\nb\n", TextConversion{}, "a\r\nb\n", false, false, [3]int{1, 1, 0}},
+		{"already LF", "a\nb\n", TextConversion{LineEnding: LF}, "a\nb\n", false, false, [3]int{2, 0, 0}},
+		{"dry run", "a\r\nb\r\n", TextConversion{LineEnding: LF, DryRun: true}, "a\r\nb\r\n", false, true, [3]int{0, 2, 0}},
+		{"binary", png, TextConversion{LineEnding: LF}, png, true, false, [3]int{}},
+		{"utf-16 is text", "\xff\xfea\x00\r\x00\n\x00", TextConversion{LineEnding: LF, Encoding: misc.UTF8},
+			"a\n", false, true, [3]int{0, 1, 0}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "file.txt")
+			if err := os.WriteFile(path, []byte(test.data), 0640); err != nil {
+				t.Fatal(err)
+			}
+			r := ConvertTextFile(path, test.conv)
+			if r.Err != nil {
+				t.Fatal(r.Err)
+			}
+			if r.Binary != test.binary || r.Changed != test.changed {
+				t.Errorf("binary %v changed %v; want %v %v", r.Binary, r.Changed, test.binary, test.changed)
+			}
+			if got := [3]int{r.LF, r.CRLF, r.CR}; got != test.counts {
+				t.Errorf("line endings %v; want %v", got, test.counts)
+			}
+			b, _ := os.ReadFile(path)
+			if string(b) != test.want {
+				t.Errorf("file = %q; want %q", b, test.want)
+			}
+			// the rewrite is atomic: no temporary file left, permissions kept
+			entries, _ := os.ReadDir(dir)
+			if len(entries) != 1 {
+				t.Errorf("%d files left in the directory; want 1", len(entries))
+			}
+			if info, err := os.Stat(path); err != nil {
+				t.Error(err)
+			} else if info.Mode().Perm() != 0640 {
+				t.Errorf("permissions after rewrite = %v; want 0640", info.Mode().Perm())
+			}
+		})
+	}
+}
+
+func TestConvertTextTree(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.txt":        "one\r\ntwo\r\n",
+		"b.md":         "one\ntwo\r\n",
+		"c.bin":        "\x00\x01\x02\x03",
+		"sub/d.txt":    "one\r\n",
+		".git/e.txt":   "one\r\n",
+		"sub/.hid.txt": "one\r\n",
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		_ = os.MkdirAll(filepath.Dir(path), 0700)
+		if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	conv := TextConversion{LineEnding: LF, Ext: []string{".txt", ".MD"}, Hidden: `^\.`, DryRun: true}
+	reports, err := ConvertTextTree(dir, conv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]string, 0, len(reports))
+	for _, r := range reports {
+		rel, _ := filepath.Rel(dir, r.Path)
+		got = append(got, filepath.ToSlash(rel)+" "+strings.TrimPrefix(r.String(), r.Path+": "))
+	}
+	want := "a.txt UTF-8 LF 0 CRLF 2 CR 0 *|b.md UTF-8 LF 1 CRLF 1 CR 0 *|sub/d.txt UTF-8 LF 0 CRLF 1 CR 0 *"
+	if s := strings.Join(got, "|"); s != want {
+		t.Errorf("dry run reports\n%s\nwant\n%s", s, want)
+	}
+	if !reports[1].Mixed() || reports[0].Mixed() {
+		t.Error("Mixed: want only b.md")
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "a.txt")); string(b) != files["a.txt"] {
+		t.Error("dry run changed a.txt")
+	}
+	conv.DryRun = false
+	if _, err = ConvertTextTree(dir, conv); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{"a.txt": "one\ntwo\n", "sub/d.txt": "one\n", ".git/e.txt": "one\r\n"} {
+		if b, _ := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name))); string(b) != want {
+			t.Errorf("%s = %q; want %q", name, b, want)
+		}
+	}
+}
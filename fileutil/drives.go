@@ -6,6 +6,8 @@ package fileutil
 import (
 	"os"
 	"syscall"
+
+	"github.com/shofster/common/misc"
 )
 
 /*
@@ -21,19 +23,33 @@ import (
 
 */
 /*
-  Description:
+  Description: The drives (mounted volumes) of unix.
+    Where the volumes are not known (darwin, BSD) the standard places.
 */
 // Unused unexported function
 //goland:noinspection GoUnusedFunction
 func getDrives() (drives []string, err error) {
+	volumes, err := misc.Volumes()
+	if err == misc.ErrNoVolumes {
+		return standardPlaces(), nil
+	}
+	if err != nil {
+		return []string{"/"}, err
+	}
+	for _, v := range volumes {
+		drives = append(drives, v.MountPoint)
+	}
+	return
+}
+
+// standardPlaces are the standard directories that exist.
+func standardPlaces() (drives []string) {
 	dirs := []string{"/", "/etc", "/home", "/media", "/mnt", "/tmp", "/usr"}
 	for _, dir := range dirs {
 		f, err := os.Open(dir)
 		if err == nil {
-			if err == nil {
-				drives = append(drives, dir)
-				_ = f.Close()
-			}
+			drives = append(drives, dir)
+			_ = f.Close()
 		}
 	}
 	return
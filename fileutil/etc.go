@@ -23,6 +23,8 @@ var DefaultHiddenFiles = "(^[^\\w].+)|(.+\\.bak)$"
 type StringList []string
 
 // Remove deletes a string from a []string.
+//
+// Deprecated: use misc.MRUList for most recently used lists.
 func Remove(sl StringList, r string) []string {
 	for i, v := range sl {
 		if v == r {
@@ -33,6 +35,8 @@ func Remove(sl StringList, r string) []string {
 }
 
 // Add appends a string to the end of a []string.
+//
+// Deprecated: use misc.MRUList for most recently used lists.
 func Add(sl []string, a string, max int) []string {
 	sl = append([]string{a}, sl...)
 	if len(sl) > max {
@@ -7,6 +7,7 @@ import (
 	"fyne.io/fyne/v2/data/binding"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+	"github.com/shofster/common/misc"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -25,8 +26,21 @@ import (
 
 */
 /*
+  Description: Select files or folders.
 
- */
+  With FileSelectFilter.Recent, the recent files and folders are shown
+    under the places and every selection is added to them (the list
+    saves itself).
+*/
+
+// RecentPlacesCapacity is how many recent files and folders OpenRecentPlaces keeps.
+var RecentPlacesCapacity = 10
+
+// OpenRecentPlaces opens the list of recent files and folders kept in dir (normally Sys.StateDir).
+//goland:noinspection GoUnusedExportedFunction
+func OpenRecentPlaces(dir string) (*misc.MRUList[string], error) {
+	return misc.OpenMRUList[string](dir, "recent-places", RecentPlacesCapacity)
+}
 
 //goland:noinspection GoUnusedExportedFunction
 func FileSelect(sel FileSelectFilter, lastDir binding.String, window fyne.Window, cb func([]string)) *widget.PopUp {
@@ -46,7 +60,7 @@ type panel struct {
 	sel           FileSelectFilter
 	cb            func([]string)
 	lastDir       binding.String
-	selected      []string
+	selected      *misc.MRUList[string]
 	parent        binding.String
 	addDir        *widget.Button
 	currentDir    binding.String
@@ -75,7 +89,11 @@ func newPanel(sel FileSelectFilter, lastDir binding.String, cb func([]string)) *
 		previousDir: binding.NewString(),
 		filename:    binding.NewString(),
 	}
-	p.selected = make([]string, 0)
+	max := 1
+	if sel.Multiple {
+		max = 1000
+	}
+	p.selected = misc.NewMRUList[string](max)
 	p.initPanel(lastDir)
 	return p
 }
@@ -157,8 +175,7 @@ func (p *panel) initPanel(lastDir binding.String) {
 			p.checkSaveFileName(name)
 			return
 		}
-		p.popup.Hide()
-		p.cb(p.selected)
+		p.finish(p.selected.Items())
 	})
 	done.Importance = widget.HighImportance
 	cancel := widget.NewButtonWithIcon("Cancel", theme.CancelIcon(), func() {
@@ -187,23 +204,29 @@ func (p *panel) checkSaveFileName(name string) {
 	path := filepath.Join(parent, name)
 	if _, err := os.Stat(path); err != nil {
 		if os.IsNotExist(err) {
-			p.popup.Hide()
-			s := make([]string, 0)
-			s = append(s, path)
-			p.cb(s)
+			p.finish([]string{path})
 			return
 		}
 	}
 	VerifyOverwrite(p.win, name, func(b bool) {
 		if b {
-			p.popup.Hide()
-			p.cb(p.selected)
+			p.finish(p.selected.Items())
 		} else {
 			_ = p.filename.Set("")
 		}
 	})
 	return
 }
+// finish hides the dialog, adds the selection to the recent places, and calls back.
+func (p *panel) finish(paths []string) {
+	p.popup.Hide()
+	if p.sel.Recent != nil {
+		for i := len(paths) - 1; i >= 0; i-- { // the first selected most recent
+			p.sel.Recent.Touch(paths[i])
+		}
+	}
+	p.cb(paths)
+}
 func (p *panel) showError(err error) {
 	list := widget.NewList(
 		func() int {
@@ -214,7 +237,7 @@ func (p *panel) showError(err error) {
 		},
 		func(id widget.ListItemID, item fyne.CanvasObject) {
 		})
-	p.selected = p.selected[:0]
+	p.selected.Clear()
 	p.addDir.Disable()
 	list.OnSelected = nil
 	list.OnUnselected = nil
@@ -229,7 +252,7 @@ func (p *panel) showDir(newPlace string) {
 	p.showList(newPlace)
 }
 func (p *panel) showList(newPlace string) {
-	p.selected = p.selected[:0]
+	p.selected.Clear()
 	p.filenameEntry.Enable()
 	p.addDir.Enable()
 	if p.lastDir != nil {
@@ -243,14 +266,10 @@ func (p *panel) showList(newPlace string) {
 			if (p.sel.FileType == Any) ||
 				(p.sel.FileType == File && !fdir.IsDir()) ||
 				(p.sel.FileType == Dir && fdir.IsDir()) {
-				p.selected = Remove(p.selected, path)
+				p.selected.Remove(path)
 				if file.IsSelected() {
 					_ = p.filename.Set(filepath.Base(path))
-					max := 1
-					if p.sel.Multiple {
-						max = 1000
-					}
-					p.selected = Add(p.selected, path, max)
+					p.selected.Touch(path)
 				} else {
 					_ = p.filename.Set("")
 				}
@@ -259,13 +278,9 @@ func (p *panel) showList(newPlace string) {
 		OnDoubleClick: func(entry FileEntry) {
 			if entry.IsDir() {
 				path := entry.Name()
-				p.selected = Remove(p.selected, path)
+				p.selected.Remove(path)
 				if entry.IsSelected() {
-					max := 1
-					if p.sel.Multiple {
-						max = 1000
-					}
-					p.selected = Add(p.selected, path, max)
+					p.selected.Touch(path)
 				}
 				p.showDir(path)
 				return
@@ -309,6 +324,30 @@ func (p *panel) buildPlaces(lastDir binding.String) *fyne.Container {
 				}))
 		}
 	}
+	if p.sel.Recent != nil {
+		for _, recent := range p.sel.Recent.Items() {
+			info, err := os.Stat(recent)
+			if err != nil {
+				continue // gone
+			}
+			n++
+			path := recent
+			icon := theme.FileIcon()
+			if info.IsDir() {
+				icon = theme.FolderIcon()
+			}
+			placeContainer.Objects = append(placeContainer.Objects,
+				widget.NewButtonWithIcon(filepath.Base(path), icon, func() {
+					if info.IsDir() {
+						p.showList(path)
+						return
+					}
+					p.showList(filepath.Dir(path))
+					p.selected.Touch(path)
+					_ = p.filename.Set(filepath.Base(path))
+				}))
+		}
+	}
 	placeContainer.Objects = append(placeContainer.Objects, widget.NewLabel(""))
 	n++
 	home, err := os.UserHomeDir()
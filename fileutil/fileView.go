@@ -5,6 +5,7 @@ import (
 	"fyne.io/fyne/v2/data/binding"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
+	"github.com/shofster/common/misc"
 )
 
 /*
@@ -75,6 +76,7 @@ type FileSelectFilter struct {
 	Date       bool
 	DtFormat   string
 	Descending bool
+	Recent     *misc.MRUList[string] // recent files and folders (see OpenRecentPlaces). nil = none
 }
 
 type FileSelectAction struct {
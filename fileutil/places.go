@@ -9,6 +9,8 @@ import (
 	"path/filepath"
 	"runtime"
 	"time"
+
+	"github.com/shofster/common/misc"
 )
 
 /*
@@ -39,12 +41,8 @@ func LoadPlaces() (drives []string) {
 	return
 }
 
-type DiskUsage struct {
-	All   uint64
-	Used  uint64
-	Free  uint64
-	Avail uint64
-}
+// DiskUsage is the size of a volume (now kept in misc).
+type DiskUsage = misc.DiskUsage
 
 //goland:noinspection GoUnusedExportedFunction
 func PrettyDiskSize(s uint64) string {
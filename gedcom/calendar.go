@@ -0,0 +1,349 @@
+package gedcom
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+/*
+
+  File:    calendar.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: The GEDCOM calendars and their Julian Day Numbers.
+
+  A Julian Day Number (JDN) counts days from 1 Jan 4713 B.C. (Julian),
+    so dates of every calendar can be compared.
+  Gregorian and Julian use the proleptic calendars, years B.C. counted
+    astronomically (1 B.C. is year 0).
+  Hebrew follows Calendrical Calculations (Dershowitz & Reingold).
+    Months are numbered in GEDCOM order, Tishri (TSH) first.
+  French Republican uses the Romme rule (leap every 4 years, starting
+    with year 3), which matches the years the calendar was in use.
+*/
+
+// Calendar of a date.
+type Calendar int
+
+const (
+	Gregorian Calendar = iota
+	Julian
+	Hebrew
+	French
+)
+
+// MinJDN and MaxJDN stand for an open end (BEF, AFT, FROM, TO).
+const (
+	MinJDN = -1 << 31
+	MaxJDN = 1<<31 - 1
+)
+
+var calendarNames = []string{"GREGORIAN", "JULIAN", "HEBREW", "FRENCH_R"}
+
+// calendarEscapes are the GEDCOM 5.5.1 forms.
+var calendarEscapes = []string{"@#DGREGORIAN@", "@#DJULIAN@", "@#DHEBREW@", "@#DFRENCH R@"}
+
+// months in GEDCOM order, for each calendar.
+var calendarMonths = [][]string{
+	{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"},
+	{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"},
+	{"TSH", "CSH", "KSL", "TVT", "SHV", "ADR", "ADS", "NSN", "IYR", "SVN", "TMZ", "AAV", "ELL"},
+	{"VEND", "BRUM", "FRIM", "NIVO", "PLUV", "VENT", "GERM", "FLOR", "PRAI", "MESS", "THER", "FRUC", "COMP"},
+}
+
+// String is the GEDCOM 7.0 name of the calendar.
+func (c Calendar) String() string {
+	if c < Gregorian || c > French {
+		return fmt.Sprintf("Calendar(%d)", int(c))
+	}
+	return calendarNames[c]
+}
+
+// Escape is the GEDCOM 5.5.1 calendar escape, "@#DJULIAN@".
+func (c Calendar) Escape() string {
+	if c < Gregorian || c > French {
+		return ""
+	}
+	return calendarEscapes[c]
+}
+
+// Months are the GEDCOM month codes of the calendar, in order.
+func (c Calendar) Months() []string {
+	if c < Gregorian || c > French {
+		return nil
+	}
+	return calendarMonths[c]
+}
+
+// Month is the 1 relative number of a GEDCOM month code. 0 if not of this calendar.
+func (c Calendar) Month(code string) int {
+	code = strings.ToUpper(code)
+	for i, m := range c.Months() {
+		if m == code {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// MonthsInYear is 12 or 13. Hebrew has 13 in a leap year; French counts the complementary days.
+func (c Calendar) MonthsInYear(year int) int {
+	switch c {
+	case Hebrew:
+		if hebrewLeap(year) {
+			return 13
+		}
+		return 12
+	case French:
+		return 13
+	}
+	return 12
+}
+
+// DaysInMonth is 0 for a month not in the year.
+func (c Calendar) DaysInMonth(year, month int) int {
+	switch c {
+	case Gregorian, Julian:
+		if month < 1 || month > 12 {
+			return 0
+		}
+		if month == 2 && c.leap(year) {
+			return 29
+		}
+		return int("\x1f\x1c\x1f\x1e\x1f\x1e\x1f\x1f\x1e\x1f\x1e\x1f"[month-1])
+	case Hebrew:
+		if month < 1 || month > 13 || (month == 7 && !hebrewLeap(year)) {
+			return 0
+		}
+		return hebrewMonthDays(year, hebrewMonths[month-1])
+	case French:
+		if month < 1 || month > 13 {
+			return 0
+		}
+		if month == 13 {
+			return frenchJDN(year+1, 1, 1) - frenchJDN(year, 13, 1)
+		}
+		return 30
+	}
+	return 0
+}
+
+// leap year of Gregorian or Julian. year is astronomical.
+func (c Calendar) leap(year int) bool {
+	if c == Julian {
+		return mod(year, 4) == 0
+	}
+	return mod(year, 4) == 0 && (mod(year, 100) != 0 || mod(year, 400) == 0)
+}
+
+// JDN is the Julian Day Number of year, month, day. year is astronomical for Gregorian
+// and Julian. The date is not checked (see DaysInMonth).
+func (c Calendar) JDN(year, month, day int) int {
+	switch c {
+	case Julian, Gregorian:
+		a := (14 - month) / 12
+		y := year + 4800 - a
+		m := month + 12*a - 3
+		jdn := day + (153*m+2)/5 + 365*y + div(y, 4)
+		if c == Julian {
+			return jdn - 32083
+		}
+		return jdn - div(y, 100) + div(y, 400) - 32045
+	case Hebrew:
+		return hebrewFixed(year, hebrewMonths[month-1], day) + rdJDN
+	case French:
+		return frenchJDN(year, month, day)
+	}
+	return 0
+}
+
+// Date is the year, month and day of a Julian Day Number.
+func (c Calendar) Date(jdn int) (year, month, day int) {
+	switch c {
+	case Julian, Gregorian:
+		f := jdn + 1401
+		if c == Gregorian {
+			f += div(div(4*jdn+274277, 146097)*3, 4) - 38
+		}
+		e := 4*f + 3
+		g := div(mod(e, 1461), 4)
+		h := 5*g + 2
+		day = div(mod(h, 153), 5) + 1
+		month = mod(div(h, 153)+2, 12) + 1
+		year = div(e, 1461) - 4716 + div(12+2-month, 12)
+	case Hebrew:
+		year, month, day = hebrewFromFixed(jdn - rdJDN)
+		for i, m := range hebrewMonths {
+			if m == month {
+				month = i + 1
+				break
+			}
+		}
+	case French:
+		year = div(4*(jdn-frenchEpoch), 1461) + 1
+		for frenchJDN(year, 1, 1) > jdn {
+			year--
+		}
+		for frenchJDN(year+1, 1, 1) <= jdn {
+			year++
+		}
+		days := jdn - frenchJDN(year, 1, 1)
+		month, day = days/30+1, days%30+1
+	}
+	return
+}
+
+// check a date. year is as written (never 0); bc for B.C.
+func (c Calendar) check(year, month, day int, bc bool) error {
+	if year < 1 {
+		return errors.New(fmt.Sprintf("invalid year %d", year))
+	}
+	if bc && (c == Hebrew || c == French) {
+		return errors.New(fmt.Sprintf("%s has no B.C. years", c))
+	}
+	if month == 0 {
+		if day != 0 {
+			return errors.New("day without a month")
+		}
+		return nil
+	}
+	y := year
+	if bc {
+		y = 1 - year
+	}
+	n := c.DaysInMonth(y, month)
+	if n == 0 {
+		return errors.New(fmt.Sprintf("%s is not in %s year %d", c.Months()[month-1], c, year))
+	}
+	if day < 0 || day > n {
+		return errors.New(fmt.Sprintf("invalid day %d of %s", day, c.Months()[month-1]))
+	}
+	return nil
+}
+
+// rdJDN converts the fixed days (RD) of Calendrical Calculations: RD 1 is 1 Jan 1 (Gregorian).
+const rdJDN = 1721425
+
+// hebrewEpoch is RD of 1 Tishri AM 1.
+const hebrewEpoch = -1373427
+
+// hebrewMonths are the Calendrical Calculations numbers (Nisan 1) of the GEDCOM months.
+var hebrewMonths = []int{7, 8, 9, 10, 11, 12, 13, 1, 2, 3, 4, 5, 6}
+
+func hebrewLeap(year int) bool {
+	return mod(7*year+1, 19) < 7
+}
+
+func hebrewElapsedDays(year int) int {
+	months := div(235*year-234, 19)
+	parts := 12084 + 13753*months
+	days := 29*months + div(parts, 25920)
+	if mod(3*(days+1), 7) < 3 {
+		return days + 1
+	}
+	return days
+}
+
+func hebrewNewYear(year int) int {
+	ny0, ny1, ny2 := hebrewElapsedDays(year-1), hebrewElapsedDays(year), hebrewElapsedDays(year+1)
+	delay := 0
+	if ny2-ny1 == 356 {
+		delay = 2
+	} else if ny1-ny0 == 382 {
+		delay = 1
+	}
+	return hebrewEpoch + ny1 + delay
+}
+
+func hebrewYearDays(year int) int {
+	return hebrewNewYear(year+1) - hebrewNewYear(year)
+}
+
+// hebrewMonthDays of a month numbered from Nisan.
+func hebrewMonthDays(year, month int) int {
+	switch {
+	case month == 2 || month == 4 || month == 6 || month == 10 || month == 13:
+		return 29
+	case month == 12 && !hebrewLeap(year):
+		return 29
+	case month == 8 && mod(hebrewYearDays(year), 10) != 5: // long Marheshvan: 355 or 385 days
+		return 29
+	case month == 9 && mod(hebrewYearDays(year), 10) == 3: // short Kislev: 353 or 383 days
+		return 29
+	}
+	return 30
+}
+
+// hebrewFixed is the RD of a date, its month numbered from Nisan.
+func hebrewFixed(year, month, day int) int {
+	last := 12
+	if hebrewLeap(year) {
+		last = 13
+	}
+	rd := hebrewNewYear(year) + day - 1
+	if month < 7 {
+		for m := 7; m <= last; m++ {
+			rd += hebrewMonthDays(year, m)
+		}
+		for m := 1; m < month; m++ {
+			rd += hebrewMonthDays(year, m)
+		}
+	} else {
+		for m := 7; m < month; m++ {
+			rd += hebrewMonthDays(year, m)
+		}
+	}
+	return rd
+}
+
+// hebrewFromFixed is the date (month numbered from Nisan) of an RD.
+func hebrewFromFixed(rd int) (year, month, day int) {
+	year = div((rd-hebrewEpoch)*98496, 35975351) + 1
+	for hebrewNewYear(year) > rd {
+		year--
+	}
+	for hebrewNewYear(year+1) <= rd {
+		year++
+	}
+	month = 7
+	if rd < hebrewFixed(year, 1, 1) {
+		for rd > hebrewFixed(year, month, hebrewMonthDays(year, month)) {
+			month++
+		}
+	} else {
+		month = 1
+		for rd > hebrewFixed(year, month, hebrewMonthDays(year, month)) {
+			month++
+		}
+	}
+	day = rd - hebrewFixed(year, month, 1) + 1
+	return
+}
+
+// frenchEpoch is the JDN before 1 Vendémiaire an I (22 Sep 1792), less the leap days.
+const frenchEpoch = 2375474
+
+func frenchJDN(year, month, day int) int {
+	return div(year*1461, 4) + (month-1)*30 + day + frenchEpoch
+}
+
+// div and mod round toward minus infinity (B.C. years).
+func div(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+func mod(a, b int) int {
+	return a - b*div(a, b)
+}
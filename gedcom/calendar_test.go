@@ -0,0 +1,83 @@
+package gedcom
+
+import (
+	"fmt"
+	"testing"
+)
+
+/*
+
+  File:    calendar_test.go
+  Author:  Bob Shofner
+
+*/
+
+func TestCalendarJDN(t *testing.T) {
+	var tests = []struct {
+		cal              Calendar
+		year, month, day int
+		want             int
+	}{
+		{Gregorian, 2000, 1, 1, 2451545},
+		{Gregorian, 1582, 10, 15, 2299161},
+		{Julian, 1582, 10, 4, 2299160},
+		{Julian, -4712, 1, 1, 0},
+		{Hebrew, 5784, 1, 1, Gregorian.JDN(2023, 9, 16)},  // Rosh Hashanah
+		{Hebrew, 5784, 8, 15, Gregorian.JDN(2024, 4, 23)}, // Passover
+		{Hebrew, 5784, 6, 14, Gregorian.JDN(2024, 2, 23)}, // Purim Katan (Adar I)
+		{Hebrew, 5784, 7, 14, Gregorian.JDN(2024, 3, 24)}, // Purim (Adar II)
+		{Hebrew, 5783, 6, 14, Gregorian.JDN(2023, 3, 7)},  // Purim
+		{French, 1, 1, 1, Gregorian.JDN(1792, 9, 22)},
+		{French, 8, 2, 18, Gregorian.JDN(1799, 11, 9)}, // 18 Brumaire
+		{French, 14, 4, 11, Gregorian.JDN(1806, 1, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %d-%d-%d", tt.cal, tt.year, tt.month, tt.day), func(t *testing.T) {
+			jdn := tt.cal.JDN(tt.year, tt.month, tt.day)
+			if jdn != tt.want {
+				t.Errorf("JDN = %d; want %d", jdn, tt.want)
+			}
+			if y, m, d := tt.cal.Date(jdn); y != tt.year || m != tt.month || d != tt.day {
+				t.Errorf("Date(%d) = %d-%d-%d", jdn, y, m, d)
+			}
+		})
+	}
+}
+
+func TestCalendarRoundTrip(t *testing.T) {
+	for c := Gregorian; c <= French; c++ {
+		start, end := Gregorian.JDN(1790, 1, 1), Gregorian.JDN(1810, 1, 1)
+		if c == Hebrew {
+			start, end = Gregorian.JDN(1990, 1, 1), Gregorian.JDN(2030, 1, 1)
+		}
+		for jdn := start; jdn < end; jdn++ {
+			y, m, d := c.Date(jdn)
+			if d < 1 || d > c.DaysInMonth(y, m) || c.JDN(y, m, d) != jdn {
+				t.Fatalf("%s: %d as %d-%d-%d", c, jdn, y, m, d)
+			}
+		}
+	}
+}
+
+func TestCalendarDays(t *testing.T) {
+	var tests = []struct {
+		cal         Calendar
+		year, month int
+		want        int
+	}{
+		{Gregorian, 1900, 2, 28},
+		{Julian, 1900, 2, 29},
+		{Gregorian, 2000, 2, 29},
+		{Hebrew, 5783, 7, 0}, // no Adar II in a common year
+		{Hebrew, 5784, 7, 29},
+		{French, 3, 13, 6},
+		{French, 4, 13, 5},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %d-%d", tt.cal, tt.year, tt.month), func(t *testing.T) {
+			if n := tt.cal.DaysInMonth(tt.year, tt.month); n != tt.want {
+				t.Errorf("Expected %d: got %d", tt.want, n)
+			}
+		})
+	}
+}
@@ -0,0 +1,442 @@
+package gedcom
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+
+  File:    date.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: Model of a GEDCOM date value (5.5.1 and 7.0).
+
+  ParseDate reads every form:
+    1 JAN 1900                     exact, or just MAR 1900, or 1900
+    ABT 1900, CAL 1900, EST 1900   approximated
+    BEF 1900, AFT 1900             ranges, and BET 1900 AND 1910
+    FROM 1900, TO 1910             periods, and FROM 1900 TO 1910
+    INT 1900 (about the war)       interpreted, with its phrase
+    (on the way to Ohio)           only a phrase
+  A date may start with its calendar, as @#DJULIAN@ (5.5.1) or JULIAN
+    (7.0), may have a dual year (11 FEB 1731/32) and may be B.C. (BCE).
+  Every date covers a range of Julian Day Numbers (Range): 1900 is from
+    1 Jan to 31 Dec. Open ends (BEF, AFT) are MinJDN and MaxJDN.
+    Approximated dates have the range of the date itself.
+  Compare orders dates by SortKey, so dates of any calendar sort together.
+*/
+
+// Qualifier of a GEDCOM date.
+type Qualifier int
+
+const (
+	Exact       Qualifier = iota
+	About                 // ABT
+	Calculated            // CAL
+	Estimated             // EST
+	Before                // BEF
+	After                 // AFT
+	Between               // BET .. AND ..
+	From                  // FROM
+	To                    // TO
+	FromTo                // FROM .. TO ..
+	Interpreted           // INT .. (phrase)
+	Phrase                // (phrase) only
+)
+
+var qualifierWords = []string{"", "ABT", "CAL", "EST", "BEF", "AFT", "BET", "FROM", "TO", "FROM", "INT", ""}
+
+func (q Qualifier) String() string {
+	if q < Exact || q > Phrase {
+		return fmt.Sprintf("Qualifier(%d)", int(q))
+	}
+	switch q {
+	case Exact:
+		return "exact"
+	case FromTo:
+		return "FROM TO"
+	case Phrase:
+		return "phrase"
+	}
+	return qualifierWords[q]
+}
+
+// qualifierAliases are accepted on input, as others write them.
+var qualifierAliases = map[string]Qualifier{
+	"ABT": About, "ABOUT": About, "CIRCA": About, "CA": About, "C": About,
+	"CAL": Calculated, "CALCULATED": Calculated,
+	"EST": Estimated, "ESTIMATED": Estimated,
+	"BEF": Before, "BEFORE": Before,
+	"AFT": After, "AFTER": After,
+	"BET": Between, "BETWEEN": Between,
+	"FROM": From, "TO": To,
+	"INT": Interpreted,
+}
+
+// CalendarDate is a single date. Day and Month may be 0 (unknown).
+type CalendarDate struct {
+	Calendar Calendar
+	Day      int
+	Month    int // 1 relative, in GEDCOM order (Calendar.Months)
+	Year     int // as written, never 0
+	DualYear int // the new style year of 1731/32 (1732). 0 if none
+	BC       bool
+}
+
+// GedcomDate is a GEDCOM date value.
+type GedcomDate struct {
+	Qualifier Qualifier
+	Date      CalendarDate // the only date, or the first of BET/AND and FROM/TO
+	Date2     CalendarDate // the second of BET/AND and FROM/TO
+	Phrase    string       // of Interpreted and Phrase
+}
+
+// ParseDate parses a GEDCOM date value. Qualifiers, month codes and calendars
+// may be in any case; Gregorian and Julian months may be any name MonthNumber knows,
+// or its unique prefix, but not the loose English prefixes (D for December).
+//goland:noinspection GoUnusedExportedFunction
+func ParseDate(s string) (GedcomDate, error) {
+	var g GedcomDate
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '('); i >= 0 {
+		j := strings.LastIndexByte(s, ')')
+		if j < i {
+			return g, errors.New(fmt.Sprintf("%q: unclosed phrase", s))
+		}
+		g.Phrase = strings.TrimSpace(s[i+1 : j])
+		s = strings.TrimSpace(s[:i] + s[j+1:])
+		if s == "" {
+			g.Qualifier = Phrase
+			return g, nil
+		}
+	}
+	if s == "" {
+		return g, errors.New("empty date")
+	}
+	tokens := dateTokens(s)
+	if q, ok := qualifierAliases[tokens[0]]; ok {
+		g.Qualifier = q
+		tokens = tokens[1:]
+	}
+	if g.Phrase != "" && g.Qualifier != Interpreted {
+		if g.Qualifier != Exact {
+			return g, errors.New(fmt.Sprintf("%q: a phrase is only allowed with INT", s))
+		}
+		g.Qualifier = Interpreted // the INT is often left off
+	}
+	var err error
+	var rest []string
+	if g.Date, rest, err = parseCalendarDate(tokens); err != nil {
+		return g, errors.New(fmt.Sprintf("%q: %v", s, err))
+	}
+	switch {
+	case g.Qualifier == Between:
+		if len(rest) == 0 || rest[0] != "AND" {
+			return g, errors.New(fmt.Sprintf("%q: BET without AND", s))
+		}
+		g.Date2, rest, err = parseCalendarDate(rest[1:])
+	case g.Qualifier == From && len(rest) > 0 && rest[0] == "TO":
+		g.Qualifier = FromTo
+		g.Date2, rest, err = parseCalendarDate(rest[1:])
+	}
+	if err != nil {
+		return g, errors.New(fmt.Sprintf("%q: %v", s, err))
+	}
+	if len(rest) > 0 {
+		return g, errors.New(fmt.Sprintf("%q: unexpected %s", s, rest[0]))
+	}
+	if g.Qualifier == Between || g.Qualifier == FromTo {
+		lo, _ := g.Date.Range()
+		_, hi := g.Date2.Range()
+		if hi < lo {
+			return g, errors.New(fmt.Sprintf("%q: second date is first", s))
+		}
+	}
+	return g, nil
+}
+
+// dateTokens upper cases and splits s, keeping @#DFRENCH R@ (and other escapes) whole.
+func dateTokens(s string) []string {
+	s = strings.ToUpper(s)
+	tokens := make([]string, 0)
+	for s != "" {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" {
+			break
+		}
+		n := strings.IndexAny(s, " \t")
+		if strings.HasPrefix(s, "@#") {
+			if e := strings.IndexByte(s[2:], '@'); e >= 0 {
+				n = e + 3
+			}
+		}
+		if n < 0 {
+			n = len(s)
+		}
+		tokens = append(tokens, s[:n])
+		s = s[n:]
+	}
+	return tokens
+}
+
+// parseCalendarDate reads [calendar] [[day] month] year[/yy] [B.C.] from tokens,
+// stopping at AND or TO.
+func parseCalendarDate(tokens []string) (d CalendarDate, rest []string, err error) {
+	if len(tokens) > 0 {
+		for c := Gregorian; c <= French; c++ {
+			if tokens[0] == c.Escape() || tokens[0] == c.String() {
+				d.Calendar = c
+				tokens = tokens[1:]
+				break
+			}
+		}
+		if len(tokens) > 0 && strings.HasPrefix(tokens[0], "@#") {
+			return d, tokens, errors.New(fmt.Sprintf("unsupported calendar %s", tokens[0])) // @#DUNKNOWN@, @#DROMAN@
+		}
+	}
+	n := 0
+	for n < len(tokens) && tokens[n] != "AND" && tokens[n] != "TO" {
+		n++
+	}
+	part, rest := tokens[:n], tokens[n:]
+	if len(part) > 0 {
+		switch part[len(part)-1] {
+		case "B.C.", "BC", "BCE", "B.C.E.":
+			d.BC = true
+			part = part[:len(part)-1]
+		}
+	}
+	if len(part) == 0 {
+		return d, rest, errors.New("missing date")
+	}
+	if len(part) > 3 {
+		return d, rest, errors.New(fmt.Sprintf("unexpected %s", part[0]))
+	}
+	if d.Year, d.DualYear, err = parseYear(part[len(part)-1]); err != nil {
+		return d, rest, err
+	}
+	if len(part) > 1 {
+		d.Month = d.Calendar.Month(part[len(part)-2])
+		if d.Month == 0 && (d.Calendar == Gregorian || d.Calendar == Julian) {
+			d.Month = localizedMonth(part[len(part)-2]) // as typed (MARS, Dezember)
+		}
+		if d.Month == 0 {
+			return d, rest, errors.New(fmt.Sprintf("unknown %s month %s", d.Calendar, part[len(part)-2]))
+		}
+	}
+	if len(part) > 2 {
+		if d.Day, err = strconv.Atoi(part[0]); err != nil {
+			return d, rest, errors.New(fmt.Sprintf("invalid day %s", part[0]))
+		}
+	}
+	year := d.Year
+	if d.DualYear > 0 {
+		year = d.DualYear
+	}
+	return d, rest, d.Calendar.check(year, d.Month, d.Day, d.BC)
+}
+
+// parseYear reads 1900, or the dual 1731/32 (also 1731/1732).
+func parseYear(s string) (year, dual int, err error) {
+	y, yy := s, ""
+	if i := strings.IndexByte(s, '/'); i >= 0 {
+		y, yy = s[:i], s[i+1:]
+	}
+	if year, err = strconv.Atoi(y); err != nil || year < 1 {
+		return 0, 0, errors.New(fmt.Sprintf("invalid year %s", s))
+	}
+	if yy == "" {
+		return year, 0, nil
+	}
+	n, err := strconv.Atoi(yy)
+	if err != nil || n < 0 {
+		return 0, 0, errors.New(fmt.Sprintf("invalid year %s", s))
+	}
+	switch len(yy) {
+	case 1, 2:
+		dual = year - year%100 + n
+		if dual <= year {
+			dual += 100
+		}
+	default:
+		dual = n
+	}
+	if dual != year+1 {
+		return 0, 0, errors.New(fmt.Sprintf("invalid dual year %s", s))
+	}
+	return year, dual, nil
+}
+
+// String is the GEDCOM 5.5.1 form, "@#DJULIAN@ 11 FEB 1731/32".
+func (d CalendarDate) String() string {
+	var b strings.Builder
+	if d.Calendar != Gregorian {
+		b.WriteString(d.Calendar.Escape())
+		b.WriteByte(' ')
+	}
+	if d.Day > 0 {
+		b.WriteString(strconv.Itoa(d.Day))
+		b.WriteByte(' ')
+	}
+	if months := d.Calendar.Months(); d.Month > 0 && d.Month <= len(months) {
+		b.WriteString(months[d.Month-1])
+		b.WriteByte(' ')
+	}
+	b.WriteString(d.YearString())
+	return b.String()
+}
+
+// YearString is the year as written: "1900", "1731/32", "44 B.C.".
+func (d CalendarDate) YearString() string {
+	s := strconv.Itoa(d.Year)
+	if d.DualYear > 0 {
+		s += fmt.Sprintf("/%02d", d.DualYear%100)
+	}
+	if d.BC {
+		s += " B.C."
+	}
+	return s
+}
+
+// IsZero reports whether the date is not set.
+func (d CalendarDate) IsZero() bool {
+	return d.Year == 0
+}
+
+// Range is the first and last Julian Day Number of the date.
+func (d CalendarDate) Range() (first, last int) {
+	if d.IsZero() {
+		return MinJDN, MaxJDN
+	}
+	c := d.Calendar
+	year := d.Year
+	if d.DualYear > 0 {
+		year = d.DualYear
+	}
+	if d.BC {
+		year = 1 - year
+	}
+	switch {
+	case d.Day > 0:
+		first = c.JDN(year, d.Month, d.Day)
+		return first, first
+	case d.Month > 0:
+		first = c.JDN(year, d.Month, 1)
+		return first, first + c.DaysInMonth(year, d.Month) - 1
+	}
+	if d.DualYear > 0 { // the old style year, 25 Mar to 24 Mar
+		return c.JDN(year-1, 3, 25), c.JDN(year, 3, 24)
+	}
+	return c.JDN(year, 1, 1), c.JDN(year+1, 1, 1) - 1
+}
+
+// String is the GEDCOM 5.5.1 form of the date value.
+func (g GedcomDate) String() string {
+	phrase := ""
+	if g.Phrase != "" {
+		phrase = "(" + g.Phrase + ")"
+	}
+	switch g.Qualifier {
+	case Exact:
+		return g.Date.String()
+	case Phrase:
+		return phrase
+	case Between:
+		return fmt.Sprintf("BET %s AND %s", g.Date, g.Date2)
+	case FromTo:
+		return fmt.Sprintf("FROM %s TO %s", g.Date, g.Date2)
+	case Interpreted:
+		return strings.TrimSpace(fmt.Sprintf("INT %s %s", g.Date, phrase))
+	}
+	if g.Qualifier < Exact || g.Qualifier > Phrase {
+		return g.Date.String()
+	}
+	return qualifierWords[g.Qualifier] + " " + g.Date.String()
+}
+
+// HasDate reports whether there is a date (not only a phrase).
+func (g GedcomDate) HasDate() bool {
+	return g.Qualifier != Phrase && !g.Date.IsZero()
+}
+
+// Range is the first and last Julian Day Number the date value may be.
+// ok is false without a date.
+func (g GedcomDate) Range() (first, last int, ok bool) {
+	if !g.HasDate() {
+		return MinJDN, MaxJDN, false
+	}
+	first, last = g.Date.Range()
+	switch g.Qualifier {
+	case Before:
+		return MinJDN, first - 1, true
+	case After:
+		return last + 1, MaxJDN, true
+	case From:
+		return first, MaxJDN, true
+	case To:
+		return MinJDN, last, true
+	case Between, FromTo:
+		_, last = g.Date2.Range()
+	}
+	return first, last, true
+}
+
+// SortKey is the Julian Day Number a date sorts by: the start of its
+// range, or the end when open at the start (BEF, TO). Dates without one
+// (phrases) sort last.
+func (g GedcomDate) SortKey() int {
+	first, last, ok := g.Range()
+	switch {
+	case !ok:
+		return MaxJDN
+	case first == MinJDN:
+		return last
+	}
+	return first
+}
+
+// Compare returns -1, 0 or +1 as g sorts before, with or after o.
+func (g GedcomDate) Compare(o GedcomDate) int {
+	if a, b := g.SortKey(), o.SortKey(); a != b {
+		return sign(a - b)
+	}
+	_, a, _ := g.Range()
+	_, b, _ := o.Range()
+	if a != b {
+		return sign(a - b)
+	}
+	if g.Qualifier != o.Qualifier {
+		return sign(int(g.Qualifier) - int(o.Qualifier))
+	}
+	return strings.Compare(g.Phrase, o.Phrase)
+}
+
+// SortDates sorts dates by Compare. Equal dates keep their order.
+//goland:noinspection GoUnusedExportedFunction
+func SortDates(dates []GedcomDate) {
+	sort.SliceStable(dates, func(i, j int) bool {
+		return dates[i].Compare(dates[j]) < 0
+	})
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
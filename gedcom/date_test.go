@@ -0,0 +1,150 @@
+package gedcom
+
+import (
+	"testing"
+)
+
+/*
+
+  File:    date_test.go
+  Author:  Bob Shofner
+
+*/
+
+func TestParseDate(t *testing.T) {
+	var tests = []struct {
+		date string
+		want string // String() of the parsed date. "" for an error
+	}{
+		{"1 jan 1900", "1 JAN 1900"},
+		{"MAR 1900", "MAR 1900"},
+		{"1900", "1900"},
+		{"3 mars 1900", "3 MAR 1900"},
+		{"Dezember 1900", "DEC 1900"},
+		{"abt 1900", "ABT 1900"},
+		{"Circa 1900", "ABT 1900"},
+		{"CAL 1900", "CAL 1900"},
+		{"EST 1900", "EST 1900"},
+		{"BEF 3 MAR 1900", "BEF 3 MAR 1900"},
+		{"after 1900", "AFT 1900"},
+		{"BET 1900 AND 1910", "BET 1900 AND 1910"},
+		{"FROM 1900", "FROM 1900"},
+		{"TO 1910", "TO 1910"},
+		{"FROM 1900 TO 1910", "FROM 1900 TO 1910"},
+		{"INT 1900 (about the war)", "INT 1900 (about the war)"},
+		{"1900 (about the war)", "INT 1900 (about the war)"},
+		{"(on the way to Ohio)", "(on the way to Ohio)"},
+		{"11 FEB 1731/32", "11 FEB 1731/32"},
+		{"29 FEB 1747/48", "29 FEB 1747/48"},
+		{"1699/1700", "1699/00"},
+		{"44 B.C.", "44 B.C."},
+		{"15 MAR 44 BCE", "15 MAR 44 B.C."},
+		{"@#DJULIAN@ 1 JAN 1700", "@#DJULIAN@ 1 JAN 1700"},
+		{"JULIAN 1 JAN 1700", "@#DJULIAN@ 1 JAN 1700"},
+		{"@#DHEBREW@ 1 TSH 5784", "@#DHEBREW@ 1 TSH 5784"},
+		{"@#DFRENCH R@ 18 BRUM 8", "@#DFRENCH R@ 18 BRUM 8"},
+		{"FRENCH_R COMP 3", "@#DFRENCH R@ COMP 3"},
+		{"BET @#DJULIAN@ 1700 AND 1710", "BET @#DJULIAN@ 1700 AND 1710"},
+		{"", ""},
+		{"30 FEB 1900", ""},
+		{"29 FEB 1900", ""},
+		{"@#DHEBREW@ 1 ADS 5783", ""},
+		{"@#DHEBREW@ 1 TSH 5784 B.C.", ""},
+		{"1 XYZ 1900", ""},
+		{"1 DUNNO 1900", ""},
+		{"SOMETIME 1900", ""},
+		{"1 sept 1900", "1 SEP 1900"},
+		{"dezemb 1900", "DEC 1900"},
+		{"@#DUNKNOWN@ 1900", ""},
+		{"@#DROMAN@ 1 JAN 1900", ""},
+		{"@#DGREGORIAN@", ""},
+		{"BET 1910 AND 1900", ""},
+		{"BET 1900", ""},
+		{"ABT 1900 (phrase)", ""},
+		{"1731/33", ""},
+		{"1 2 3 1900", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.date, func(t *testing.T) {
+			g, err := ParseDate(tt.date)
+			switch {
+			case tt.want == "" && err == nil:
+				t.Errorf("Expected an error: got %s", g)
+			case tt.want != "" && err != nil:
+				t.Errorf("Expected %s: got %v", tt.want, err)
+			case err == nil && g.String() != tt.want:
+				t.Errorf("Expected %s: got %s", tt.want, g)
+			}
+		})
+	}
+}
+
+func TestDateRange(t *testing.T) {
+	var tests = []struct {
+		date        string
+		first, last int
+	}{
+		{"1900", Gregorian.JDN(1900, 1, 1), Gregorian.JDN(1900, 12, 31)},
+		{"FEB 1900", Gregorian.JDN(1900, 2, 1), Gregorian.JDN(1900, 2, 28)},
+		{"@#DJULIAN@ FEB 1900", Julian.JDN(1900, 2, 1), Julian.JDN(1900, 2, 29)},
+		{"BEF 1900", MinJDN, Gregorian.JDN(1899, 12, 31)},
+		{"AFT 1900", Gregorian.JDN(1901, 1, 1), MaxJDN},
+		{"BET 1900 AND MAR 1910", Gregorian.JDN(1900, 1, 1), Gregorian.JDN(1910, 3, 31)},
+		{"11 FEB 1731/32", Gregorian.JDN(1732, 2, 11), Gregorian.JDN(1732, 2, 11)},
+		{"1731/32", Gregorian.JDN(1731, 3, 25), Gregorian.JDN(1732, 3, 24)},
+		{"1 B.C.", Gregorian.JDN(0, 1, 1), Gregorian.JDN(0, 12, 31)},
+		{"@#DHEBREW@ 5784", Gregorian.JDN(2023, 9, 16), Gregorian.JDN(2024, 10, 2)},
+		{"@#DFRENCH R@ 1", Gregorian.JDN(1792, 9, 22), Gregorian.JDN(1793, 9, 21)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.date, func(t *testing.T) {
+			g, err := ParseDate(tt.date)
+			if err != nil {
+				t.Fatal(err)
+			}
+			first, last, ok := g.Range()
+			if !ok || first != tt.first || last != tt.last {
+				t.Errorf("Range = %d, %d, %t; want %d, %d", first, last, ok, tt.first, tt.last)
+			}
+		})
+	}
+	if _, _, ok := (GedcomDate{Qualifier: Phrase, Phrase: "unknown"}).Range(); ok {
+		t.Error("a phrase has a range")
+	}
+}
+
+func TestSortDates(t *testing.T) {
+	in := []string{
+		"(unknown)",
+		"AFT 1900",
+		"1900",
+		"@#DJULIAN@ 20 DEC 1899", // 1 Jan 1900 Gregorian
+		"BEF 1900",
+		"MAR 1899",
+		"@#DHEBREW@ 1 TSH 5660", // 5 Sep 1899
+		"FROM 1899 TO 1901",
+	}
+	want := []string{
+		"FROM 1899 TO 1901",
+		"MAR 1899",
+		"@#DHEBREW@ 1 TSH 5660",
+		"BEF 1900",
+		"@#DJULIAN@ 20 DEC 1899", // same start, ends first
+		"1900",
+		"AFT 1900",
+		"(unknown)",
+	}
+	dates := make([]GedcomDate, len(in))
+	for i, s := range in {
+		var err error
+		if dates[i], err = ParseDate(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	SortDates(dates)
+	for i, g := range dates {
+		if g.String() != want[i] {
+			t.Errorf("%d: Expected %s: got %s", i, want[i], g)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package gedcom
+
+import (
+	"sort"
+
+	"github.com/shofster/common/misc"
+)
+
+/*
+
+  File:    file.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: The records of a GEDCOM file, indexed by cross-reference.
+*/
+
+// File is every record of a GEDCOM file, in order. HEAD is first; TRLR is not kept.
+type File struct {
+	Records  []*Node
+	Encoding misc.EncodingHint // read from
+	index    map[string]*Node
+}
+
+// NewFile creates a file without records.
+//goland:noinspection GoUnusedExportedFunction
+func NewFile() *File {
+	return &File{index: make(map[string]*Node)}
+}
+
+// Add a record to the end.
+func (f *File) Add(record *Node) {
+	f.Records = append(f.Records, record)
+	if record.Xref != "" {
+		f.index[record.Xref] = record
+	}
+}
+
+// Remove the record xref. false if none.
+func (f *File) Remove(xref string) bool {
+	record, ok := f.index[xref]
+	if !ok {
+		return false
+	}
+	delete(f.index, xref)
+	for i, r := range f.Records {
+		if r == record {
+			f.Records = append(f.Records[:i], f.Records[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// Header is the HEAD record. nil if none.
+func (f *File) Header() *Node {
+	for _, r := range f.Records {
+		if r.Tag == "HEAD" {
+			return r
+		}
+	}
+	return nil
+}
+
+// Version is HEAD.GEDC.VERS ("5.5.1", "7.0").
+func (f *File) Version() string {
+	if h := f.Header(); h != nil {
+		return h.PathValue("GEDC", "VERS")
+	}
+	return ""
+}
+
+// Record is the record with the cross-reference xref ("@I1@"). nil if none.
+func (f *File) Record(xref string) *Node {
+	return f.index[xref]
+}
+
+// All are the records with tag ("INDI").
+func (f *File) All(tag string) []*Node {
+	records := make([]*Node, 0)
+	for _, r := range f.Records {
+		if r.Tag == tag {
+			records = append(records, r)
+		}
+	}
+	return records
+}
+
+// Resolve is the record n points to (FAMS @F1@ as the FAM record). nil if none.
+func (f *File) Resolve(n *Node) *Node {
+	if p := n.Pointer(); p != "" {
+		return f.index[p]
+	}
+	return nil
+}
+
+// Unresolved are the pointers to records not in the file (sorted). @VOID@ is not one.
+func (f *File) Unresolved() []string {
+	missing := make(map[string]bool)
+	for _, r := range f.Records {
+		r.Walk(func(n *Node) bool {
+			if p := n.Pointer(); p != "" && p != "@VOID@" && f.index[p] == nil {
+				missing[p] = true
+			}
+			return true
+		})
+	}
+	xrefs := make([]string, 0, len(missing))
+	for p := range missing {
+		xrefs = append(xrefs, p)
+	}
+	sort.Strings(xrefs)
+	return xrefs
+}
@@ -0,0 +1,136 @@
+package gedcom
+
+import (
+	"strings"
+
+	"github.com/shofster/common/misc"
+)
+
+/*
+
+  File:    month.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: Month names as people type them.
+
+  MonthNumber knows the English, French, German, Spanish and Dutch
+    names and their abbreviations, with or without accents (févr, fevr)
+    and a trailing period. A unique prefix of at least 3 letters will do
+    (sept, dezemb). Failing those, the English prefix rules apply: JA is
+    January, F February, AP April, AU August, S September, O October,
+    N November and D December.
+  ParseDate takes only the names and unique prefixes, so a stray word is
+    not read as a month.
+*/
+
+var monthAbbrev = []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+
+// monthNames are the names and abbreviations (accents folded), per month.
+var monthNames = [][]string{
+	{"JANUARY", "JANVIER", "JANV", "JANUAR", "JANNER", "ENERO", "ENE", "JANUARI"},
+	{"FEBRUARY", "FEVRIER", "FEVR", "FEV", "FEBRUAR", "FEBRERO", "FEBRUARI"},
+	{"MARCH", "MARS", "MARZ", "MAERZ", "MARZO", "MAART", "MRT"},
+	{"APRIL", "AVRIL", "AVR", "ABRIL", "ABR"},
+	{"MAY", "MAI", "MAYO", "MEI"},
+	{"JUNE", "JUIN", "JUNI", "JUNIO"},
+	{"JULY", "JUILLET", "JUIL", "JULI", "JULIO"},
+	{"AUGUST", "AOUT", "AGOSTO", "AGO", "AUGUSTUS"},
+	{"SEPTEMBER", "SEPTEMBRE", "SEPT", "SEPTIEMBRE", "SETIEMBRE", "SET"},
+	{"OCTOBER", "OCTOBRE", "OKTOBER", "OKT", "OCTUBRE"},
+	{"NOVEMBER", "NOVEMBRE", "NOVIEMBRE"},
+	{"DECEMBER", "DECEMBRE", "DEC", "DEZEMBER", "DEZ", "DICIEMBRE", "DIC"},
+}
+
+// MonthNumber is the number (1 thru 12) of a month name. 0 if unknown.
+//goland:noinspection GoUnusedExportedFunction
+func MonthNumber(s string) int {
+	if n := localizedMonth(s); n > 0 {
+		return n
+	}
+	return englishMonth(foldMonth(s))
+}
+
+// localizedMonth is the number of a month name or its unique prefix of at
+// least 3 letters, without the English prefix rules. 0 if unknown.
+func localizedMonth(s string) int {
+	m := foldMonth(s)
+	if m == "" {
+		return 0
+	}
+	for i, names := range monthNames {
+		for _, name := range names {
+			if m == name {
+				return i + 1
+			}
+		}
+	}
+	if len(m) < 3 {
+		return 0
+	}
+	found := 0
+	for i, names := range monthNames {
+		for _, name := range names {
+			if strings.HasPrefix(name, m) && found != i+1 {
+				if found != 0 {
+					return 0 // more than one month
+				}
+				found = i + 1
+			}
+		}
+	}
+	return found
+}
+
+// foldMonth upper cases s without accents, spaces and a trailing period.
+func foldMonth(s string) string {
+	return strings.ReplaceAll(misc.FoldName(strings.TrimSuffix(strings.TrimSpace(s), ".")), " ", "")
+}
+
+// NormalizeMonth is the abbreviation (Jan) of a month name. Unknown names are
+// returned upper cased.
+//goland:noinspection GoUnusedExportedFunction
+func NormalizeMonth(s string) string {
+	if n := MonthNumber(s); n > 0 {
+		return monthAbbrev[n-1]
+	}
+	return strings.TrimSpace(strings.ToUpper(s))
+}
+
+// englishMonth are the prefix rules of the original month normalizer.
+func englishMonth(m string) int {
+	switch {
+	case strings.HasPrefix(m, "JA"):
+		return 1
+	case strings.HasPrefix(m, "F"):
+		return 2
+	case strings.HasPrefix(m, "MAR"):
+		return 3
+	case strings.HasPrefix(m, "AP"):
+		return 4
+	case strings.HasPrefix(m, "MAY"):
+		return 5
+	case strings.HasPrefix(m, "JUN"):
+		return 6
+	case strings.HasPrefix(m, "JUL"):
+		return 7
+	case strings.HasPrefix(m, "AU"):
+		return 8
+	case strings.HasPrefix(m, "S"):
+		return 9
+	case strings.HasPrefix(m, "O"):
+		return 10
+	case strings.HasPrefix(m, "N"):
+		return 11
+	case strings.HasPrefix(m, "D"):
+		return 12
+	}
+	return 0
+}
@@ -0,0 +1,70 @@
+package gedcom
+
+import (
+	"testing"
+)
+
+/*
+
+  File:    month_test.go
+  Author:  Bob Shofner
+
+*/
+
+func TestMonthNumber(t *testing.T) {
+	var tests = []struct {
+		name string
+		want int
+	}{
+		// English, and the original prefix rules
+		{"January", 1}, {"jan", 1}, {"Ja", 1}, {"feb", 2}, {"F", 2}, {"March", 3}, {"Mar.", 3},
+		{"apr", 4}, {"May", 5}, {"JUN", 6}, {"July", 7}, {"Aug", 8}, {"sep", 9}, {"S", 9},
+		{"Oct", 10}, {"O", 10}, {"nov", 11}, {"December", 12}, {"D", 12},
+		// French
+		{"janvier", 1}, {"janv.", 1}, {"février", 2}, {"fevrier", 2}, {"févr", 2}, {"mars", 3},
+		{"avril", 4}, {"avr", 4}, {"mai", 5}, {"juin", 6}, {"juillet", 7}, {"juil.", 7},
+		{"août", 8}, {"aout", 8}, {"septembre", 9}, {"octobre", 10}, {"novembre", 11}, {"décembre", 12}, {"déc", 12},
+		// German
+		{"Januar", 1}, {"Jänner", 1}, {"Februar", 2}, {"März", 3}, {"Maerz", 3}, {"April", 4}, {"Mai", 5},
+		{"Juni", 6}, {"Juli", 7}, {"August", 8}, {"September", 9}, {"Oktober", 10}, {"Okt", 10},
+		{"November", 11}, {"Dezember", 12}, {"Dez", 12},
+		// Spanish
+		{"enero", 1}, {"ene", 1}, {"febrero", 2}, {"marzo", 3}, {"abril", 4}, {"abr", 4}, {"mayo", 5},
+		{"junio", 6}, {"julio", 7}, {"agosto", 8}, {"ago", 8}, {"septiembre", 9}, {"setiembre", 9},
+		{"octubre", 10}, {"noviembre", 11}, {"diciembre", 12}, {"dic", 12},
+		// Dutch
+		{"januari", 1}, {"februari", 2}, {"maart", 3}, {"mrt", 3}, {"april", 4}, {"mei", 5},
+		{"juni", 6}, {"juli", 7}, {"augustus", 8}, {"september", 9}, {"oktober", 10},
+		{"november", 11}, {"december", 12},
+		// unique prefixes, and not
+		{"sept", 9}, {"dezemb", 12}, {"juil", 7}, {"jui", 0}, {"ma", 0},
+		{"", 0}, {"xyz", 0}, {"13", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if n := MonthNumber(tt.name); n != tt.want {
+				t.Errorf("Expected %d: got %d", tt.want, n)
+			}
+		})
+	}
+}
+
+func TestNormalizeMonth(t *testing.T) {
+	var tests = []struct {
+		name string
+		want string
+	}{
+		{"january", "Jan"},
+		{"MÄRZ", "Mar"},
+		{"mei", "May"},
+		{"ago", "Aug"},
+		{" xyz ", "XYZ"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if m := NormalizeMonth(tt.name); m != tt.want {
+				t.Errorf("Expected %s: got %s", tt.want, m)
+			}
+		})
+	}
+}
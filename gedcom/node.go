@@ -0,0 +1,120 @@
+package gedcom
+
+import (
+	"strings"
+)
+
+/*
+
+  File:    node.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: The record tree of a GEDCOM file.
+
+  Every line is a Node; its subordinate lines are its Children. A level
+    0 Node is a record (HEAD, INDI, FAM, SOUR, NOTE ...), which may have
+    a cross-reference identifier (Xref, "@I1@") other records point to.
+  CONT and CONC lines are not Nodes: they are joined into the Value of
+    the line they continue, a CONT as a new line ("\n").
+  Tags are kept as read, so unknown (and _custom) tags are written back.
+*/
+
+// Node is one line of a GEDCOM file with its subordinate lines.
+type Node struct {
+	Xref     string // "@I1@". records only
+	Tag      string
+	Value    string // a pointer ("@F1@"), or text with CONT and CONC joined
+	Children []*Node
+}
+
+// NewNode creates a node without children.
+//goland:noinspection GoUnusedExportedFunction
+func NewNode(tag, value string) *Node {
+	return &Node{Tag: tag, Value: value}
+}
+
+// Add a child; returns it.
+func (n *Node) Add(tag, value string) *Node {
+	c := NewNode(tag, value)
+	n.Children = append(n.Children, c)
+	return c
+}
+
+// Child is the first child with tag. nil if none.
+func (n *Node) Child(tag string) *Node {
+	for _, c := range n.Children {
+		if c.Tag == tag {
+			return c
+		}
+	}
+	return nil
+}
+
+// All are the children with tag.
+func (n *Node) All(tag string) []*Node {
+	nodes := make([]*Node, 0)
+	for _, c := range n.Children {
+		if c.Tag == tag {
+			nodes = append(nodes, c)
+		}
+	}
+	return nodes
+}
+
+// Path is the node found by following tags ("BIRT", "DATE"). nil if none.
+func (n *Node) Path(tags ...string) *Node {
+	for _, tag := range tags {
+		if n = n.Child(tag); n == nil {
+			return nil
+		}
+	}
+	return n
+}
+
+// PathValue is the Value of Path. "" if none.
+func (n *Node) PathValue(tags ...string) string {
+	if c := n.Path(tags...); c != nil {
+		return c.Value
+	}
+	return ""
+}
+
+// Pointer is the cross-reference Value points to ("@F1@"). "" if not a pointer.
+func (n *Node) Pointer() string {
+	if isXref(n.Value) {
+		return n.Value
+	}
+	return ""
+}
+
+// Date parses the value of the DATE child.
+func (n *Node) Date() (GedcomDate, error) {
+	return ParseDate(n.PathValue("DATE"))
+}
+
+// Walk calls fn for n and every node below it, depth first, until fn returns false.
+func (n *Node) Walk(fn func(*Node) bool) bool {
+	if !fn(n) {
+		return false
+	}
+	for _, c := range n.Children {
+		if !c.Walk(fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// isXref reports whether s is "@" id "@" (not "@#D ...@" or the "@@" escape).
+func isXref(s string) bool {
+	return len(s) > 2 && s[0] == '@' && s[len(s)-1] == '@' && s[1] != '#' &&
+		!strings.ContainsAny(s[1:len(s)-1], "@ ")
+}
@@ -0,0 +1,100 @@
+package gedcom
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+/*
+
+  File:    phrase.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: Tidy a date as typed into the GEDCOM form ("1 Jan 1900").
+
+  3 forms of a GEDCOM date:
+    [[dd ]Mon ]yyyy
+    ABT, BEF, AFT or EST date
+    BET date AND date
+  The month may be any name MonthNumber knows.
+  See ParseDate for the full date model.
+*/
+
+// find first 4 digit number
+var yearOnly = regexp.MustCompile("\\d{4}")
+
+// GetYear is the first 4 digit number of a date phrase. "" if none.
+//goland:noinspection GoUnusedExportedFunction
+func GetYear(datePhrase string) string {
+	year := yearOnly.FindString(datePhrase)
+	return year
+}
+
+var dateMod = regexp.MustCompile("(?i)^(ABT|BEF|AFT|EST)\\s+(.*)")
+var dateRange = regexp.MustCompile("(?i)^BET\\s*(.*?)\\s*AND\\s*(.*)")
+
+// GetGedcomString tidies a date: "abt 3 mars 1900" as "ABT 3 Mar 1900".
+// The date is returned unchanged with an error if it is not one of the forms.
+//goland:noinspection GoUnusedExportedFunction
+func GetGedcomString(dateString string) (string, error) {
+	t := strings.TrimSpace(dateString)
+	// see if date Modifier
+	if modDates := dateMod.FindStringSubmatch(t); modDates != nil {
+		// mod is one of ABT|BEF|AFT|EST
+		if g, err := getExactGedcomString(modDates[2]); err == nil {
+			return fmt.Sprintf("%s %s", strings.ToUpper(modDates[1]), g), nil
+		}
+		return dateString, errors.New("incomplete mod type")
+	}
+	// see if a BET / AND phrase
+	if fromTo := dateRange.FindStringSubmatch(t); fromTo != nil {
+		// BET and AND are used and ignored
+		if from, ef := getExactGedcomString(fromTo[1]); ef == nil {
+			if to, et := getExactGedcomString(fromTo[2]); et == nil {
+				return fmt.Sprintf("BET %s AND %s", from, to), nil
+			}
+		}
+		return dateString, errors.New("incomplete range type")
+	}
+	switch p := strings.ToLower(t); {
+	case strings.HasPrefix(p, "abt"), strings.HasPrefix(p, "bef"), strings.HasPrefix(p, "aft"), strings.HasPrefix(p, "est"):
+		return dateString, errors.New("incomplete mod type")
+	case strings.HasPrefix(p, "bet"):
+		return dateString, errors.New("incomplete range type")
+	}
+	// a 1 to 3  (dd mmm yyyy) part GEDCOM date
+	g, err := getExactGedcomString(t)
+	if err != nil {
+		return dateString, err
+	}
+	return g, nil
+}
+
+func getExactGedcomString(dateString string) (string, error) {
+	parts := strings.Fields(dateString)
+	if len(parts) == 0 {
+		return "", errors.New("empty")
+	}
+	if GetYear(dateString) == "" {
+		return "", errors.New("missing year")
+	}
+	switch len(parts) {
+	case 1:
+		return fmt.Sprintf("%4s", parts[0]), nil
+	case 2:
+		return fmt.Sprintf("%3s %4s", NormalizeMonth(parts[0]), parts[1]), nil
+	case 3:
+		return fmt.Sprintf("%s %3s %4s", parts[0], NormalizeMonth(parts[1]), parts[2]), nil
+	}
+	return "", errors.New("invalid")
+}
@@ -0,0 +1,81 @@
+package gedcom
+
+import (
+	"testing"
+)
+
+/*
+
+  File:    phrase_test.go
+  Author:  Bob Shofner
+
+*/
+
+func TestGetYear(t *testing.T) {
+	var tests = []struct {
+		phrase string
+		want   string
+	}{
+		{"1 Jan 1900", "1900"},
+		{"BET 1900 AND 1910", "1900"},
+		{"abt 3 mars 1850", "1850"},
+		{"12 Mar 99", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.phrase, func(t *testing.T) {
+			if y := GetYear(tt.phrase); y != tt.want {
+				t.Errorf("Expected %q: got %q", tt.want, y)
+			}
+		})
+	}
+}
+
+func TestGetGedcomString(t *testing.T) {
+	var tests = []struct {
+		date string
+		want string
+		err  bool
+	}{
+		// [[dd ]Mon ]yyyy
+		{"1900", "1900", false},
+		{" 1900 ", "1900", false},
+		{"jan 1900", "Jan 1900", false},
+		{"1 january 1900", "1 Jan 1900", false},
+		{"1 JAN 1900", "1 Jan 1900", false},
+		{"3 mars 1900", "3 Mar 1900", false},
+		{"3 März 1900", "3 Mar 1900", false},
+		{"3 marzo 1900", "3 Mar 1900", false},
+		{"3 maart 1900", "3 Mar 1900", false},
+		{"14 juillet 1789", "14 Jul 1789", false},
+		{"24 Dezember 1900", "24 Dec 1900", false},
+		{"6 de enero 1900", "6 de enero 1900", true},
+		// ABT, BEF, AFT, EST
+		{"abt 1900", "ABT 1900", false},
+		{"Bef 1 mei 1900", "BEF 1 May 1900", false},
+		{"AFT août 1900", "AFT Aug 1900", false},
+		{"est 1900", "EST 1900", false},
+		{"abt", "abt", true},
+		{"abt1900", "abt1900", true},
+		// BET .. AND ..
+		{"bet 1900 and 1910", "BET 1900 AND 1910", false},
+		{"BET jan 1900 AND dic 1910", "BET Jan 1900 AND Dec 1910", false},
+		{"bet 1900", "bet 1900", true},
+		{"bet 1900 and", "bet 1900 and", true},
+		// errors
+		{"", "", true},
+		{"Jan", "Jan", true},
+		{"1 2 3 4", "1 2 3 4", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.date, func(t *testing.T) {
+			g, err := GetGedcomString(tt.date)
+			if (err != nil) != tt.err {
+				t.Errorf("error %v; want error %t", err, tt.err)
+			}
+			if g != tt.want {
+				t.Errorf("Expected %q: got %q", tt.want, g)
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package gedcom
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/shofster/common/misc"
+)
+
+/*
+
+  File:    reader.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: Read a GEDCOM (5.5.1 or 7.0) file.
+
+  A Reader returns one record at a time (Next), so a large file need not
+    be held in memory. Read and ReadFile collect every record in a File,
+    which indexes them by cross-reference.
+  The encoding is found from a byte order mark (UTF-8, UTF-16 or UTF-32),
+    else from HEAD.CHAR (ANSEL, UTF-8, ANSI, IBMPC, MACINTOSH ...), else
+    guessed by misc.DetectEncoding. GEDCOM 7.0 is always UTF-8.
+  Lines may end in CR, LF or both, and may be indented.
+*/
+
+// detectSize is how much of the file is examined for the encoding.
+const detectSize = 16 * 1024
+
+// maxLine is the longest line read (GEDCOM 7.0 has no limit).
+const maxLine = 1024 * 1024
+
+// charEncodings are the HEAD.CHAR values, and what they are.
+var charEncodings = map[string]misc.EncodingHint{
+	"ANSEL":       misc.ANSEL,
+	"UTF-8":       misc.UTF8,
+	"UTF8":        misc.UTF8,
+	"UNICODE":     misc.UTF16LE,
+	"ANSI":        misc.CP1252,
+	"WINDOWS":     misc.CP1252,
+	"IBMPC":       misc.IBM437,
+	"IBM WINDOWS": misc.CP1252,
+	"MACINTOSH":   misc.MACROMAN,
+	"LATIN1":      misc.ISO8859_1,
+	"ISO-8859-1":  misc.ISO8859_1,
+}
+
+var headChar = regexp.MustCompile(`(?m)^[ \t]*1[ \t]+CHAR[ \t]+([^\r\n]+)`)
+
+// Reader reads the records of a GEDCOM file.
+type Reader struct {
+	Encoding misc.EncodingHint // of the file
+	scanner  *bufio.Scanner
+	line     int
+	next     *line // the level 0 line read ahead
+	done     bool
+}
+
+// line is one parsed line of the file.
+type line struct {
+	number int
+	level  int
+	xref   string
+	tag    string
+	value  string
+}
+
+// NewReader detects the encoding of r and prepares to read its records.
+//goland:noinspection GoUnusedExportedFunction
+func NewReader(r io.Reader) *Reader {
+	br := bufio.NewReaderSize(r, detectSize)
+	sample, _ := br.Peek(detectSize)
+	d, bom := misc.DetectEncoding(sample)
+	switch {
+	case bom:
+	case d == misc.UTF16LE || d == misc.UTF16BE || d == misc.UTF32LE || d == misc.UTF32BE:
+	default:
+		if m := headChar.FindSubmatch(sample); m != nil {
+			name := strings.ToUpper(strings.TrimSpace(string(m[1])))
+			if e, ok := charEncodings[name]; ok && e != misc.UTF16LE {
+				d = e
+			}
+		}
+	}
+	text, d := misc.NewReaderDetect(br, d)
+	scanner := bufio.NewScanner(text)
+	scanner.Buffer(make([]byte, 0, 4096), maxLine)
+	scanner.Split(scanLines)
+	return &Reader{Encoding: d, scanner: scanner}
+}
+
+// scanLines splits at CR, LF, CR LF or LF CR. Blank lines are skipped.
+func scanLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	start := 0
+	for start < len(data) && (data[start] == '\r' || data[start] == '\n') {
+		start++
+	}
+	if i := bytes.IndexAny(data[start:], "\r\n"); i >= 0 {
+		return start + i + 1, data[start : start+i], nil
+	}
+	if atEOF && start < len(data) {
+		return len(data), data[start:], nil
+	}
+	return start, nil, nil
+}
+
+// Next returns the next record. io.EOF after the last (at TRLR).
+func (r *Reader) Next() (*Node, error) {
+	if r.done {
+		return nil, io.EOF
+	}
+	first := r.next
+	r.next = nil
+	if first == nil {
+		var err error
+		if first, err = r.readLine(); err != nil {
+			return nil, err
+		}
+	}
+	if first.level != 0 {
+		return nil, errors.New(fmt.Sprintf("line %d: record without level 0", first.number))
+	}
+	if first.tag == "TRLR" {
+		r.done = true
+		return nil, io.EOF
+	}
+	record := &Node{Xref: first.xref, Tag: first.tag, Value: first.value}
+	stack := []*Node{record}
+	for {
+		l, err := r.readLine()
+		if err == io.EOF {
+			r.done = true
+			return record, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if l.level == 0 {
+			r.next = l
+			return record, nil
+		}
+		if l.level > len(stack) {
+			return nil, errors.New(fmt.Sprintf("line %d: level %d follows level %d", l.number, l.level, len(stack)-1))
+		}
+		parent := stack[l.level-1]
+		switch l.tag {
+		case "CONT":
+			parent.Value += "\n" + l.value
+			continue
+		case "CONC":
+			parent.Value += l.value
+			continue
+		}
+		n := &Node{Xref: l.xref, Tag: l.tag, Value: l.value}
+		parent.Children = append(parent.Children, n)
+		stack = append(stack[:l.level], n)
+	}
+}
+
+// readLine reads and parses the next line: level [@xref@] tag [value].
+func (r *Reader) readLine() (*line, error) {
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return nil, errors.New(fmt.Sprintf("line %d: %v", r.line+1, err))
+		}
+		return nil, io.EOF
+	}
+	r.line++
+	text := strings.TrimLeft(r.scanner.Text(), " \t\ufeff")
+	if text == "" {
+		return r.readLine()
+	}
+	l := &line{number: r.line}
+	level, text := cutField(text)
+	var err error
+	if l.level, err = strconv.Atoi(level); err != nil || l.level < 0 {
+		return nil, errors.New(fmt.Sprintf("line %d: invalid level", l.number))
+	}
+	l.tag, text = cutField(strings.TrimLeft(text, " "))
+	if isXref(l.tag) {
+		l.xref = l.tag
+		l.tag, text = cutField(strings.TrimLeft(text, " "))
+	}
+	if l.tag == "" {
+		return nil, errors.New(fmt.Sprintf("line %d: missing tag", l.number))
+	}
+	l.value = text // after the single space following the tag
+	return l, nil
+}
+
+// cutField splits s at the first space, dropping that space.
+func cutField(s string) (field, rest string) {
+	if i := strings.IndexByte(s, ' '); i >= 0 {
+		return s[:i], s[i+1:]
+	}
+	return s, ""
+}
+
+// Read every record of r.
+//goland:noinspection GoUnusedExportedFunction
+func Read(r io.Reader) (*File, error) {
+	rd := NewReader(r)
+	f := NewFile()
+	f.Encoding = rd.Encoding
+	for {
+		n, err := rd.Next()
+		if err == io.EOF {
+			return f, nil
+		}
+		if err != nil {
+			return f, err
+		}
+		f.Add(n)
+	}
+}
+
+// ReadFile reads every record of the file name.
+//goland:noinspection GoUnusedExportedFunction
+func ReadFile(name string) (*File, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	f, err := Read(file)
+	if err != nil {
+		return f, errors.New(fmt.Sprintf("%s: %v", name, err))
+	}
+	return f, nil
+}
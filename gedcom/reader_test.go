@@ -0,0 +1,144 @@
+package gedcom
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/shofster/common/misc"
+)
+
+/*
+
+  File:    reader_test.go
+  Author:  Bob Shofner
+
+*/
+
+const sample = `0 HEAD
+1 GEDC
+2 VERS 5.5.1
+2 FORM LINEAGE-LINKED
+1 CHAR UTF-8
+0 @I1@ INDI
+1 NAME Anna /Müller/
+1 _UID 0123456789ABCDEF
+1 BIRT
+2 DATE ABT 1850
+2 PLAC Köln
+1 FAMS @F1@
+1 NOTE First line
+2 CONT second line
+2 CONT
+2 CONT fourth line
+0 @I2@ INDI
+1 NAME John /Smith/
+1 FAMS @F1@
+0 @F1@ FAM
+1 HUSB @I2@
+1 WIFE @I1@
+1 CHIL @I3@
+0 @N1@ NOTE A shared note
+0 @L1@ _LOC
+1 NAME Cologne
+0 TRLR
+`
+
+func TestRead(t *testing.T) {
+	f, err := Read(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Version() != "5.5.1" || f.Encoding != misc.UTF8 {
+		t.Errorf("Version %s, Encoding %s", f.Version(), f.Encoding)
+	}
+	if n := len(f.Records); n != 6 {
+		t.Errorf("Records = %d; want 6", n)
+	}
+	anna := f.Record("@I1@")
+	if anna == nil || anna.PathValue("NAME") != "Anna /Müller/" {
+		t.Fatalf("Record(@I1@) = %v", anna)
+	}
+	if v := anna.PathValue("NOTE"); v != "First line\nsecond line\n\nfourth line" {
+		t.Errorf("NOTE = %q", v)
+	}
+	if d, err := anna.Child("BIRT").Date(); err != nil || d.Qualifier != About || d.Date.Year != 1850 {
+		t.Errorf("BIRT.DATE = %v, %v", d, err)
+	}
+	fam := f.Resolve(anna.Child("FAMS"))
+	if fam == nil || f.Resolve(fam.Child("HUSB")).PathValue("NAME") != "John /Smith/" {
+		t.Errorf("FAMS does not resolve to the family of John")
+	}
+	if u := f.Unresolved(); len(u) != 1 || u[0] != "@I3@" {
+		t.Errorf("Unresolved = %v; want [@I3@]", u)
+	}
+	if n := len(f.All("INDI")); n != 2 {
+		t.Errorf("INDI = %d; want 2", n)
+	}
+	if loc := f.Record("@L1@"); loc == nil || loc.Tag != "_LOC" {
+		t.Errorf("custom record lost")
+	}
+}
+
+func TestReadStream(t *testing.T) {
+	r := NewReader(strings.NewReader(strings.ReplaceAll(sample, "\n", "\r")))
+	tags := make([]string, 0)
+	for {
+		n, err := r.Next()
+		if err != nil {
+			break
+		}
+		tags = append(tags, n.Tag)
+	}
+	if s := strings.Join(tags, ","); s != "HEAD,INDI,INDI,FAM,NOTE,_LOC" {
+		t.Errorf("records %s", s)
+	}
+}
+
+func TestReadEncoding(t *testing.T) {
+	ansel := strings.ReplaceAll(sample, "CHAR UTF-8", "CHAR ANSEL")
+	ansel = strings.ReplaceAll(ansel, "Müller", "M\xe8uller")
+	ansel = strings.ReplaceAll(ansel, "Köln", "K\xe8oln")
+	var utf16 bytes.Buffer
+	w := misc.NewWriter(&utf16, misc.UTF16LE, misc.WriteOptions{BOM: true, CRLF: true})
+	_, _ = w.Write([]byte(strings.ReplaceAll(sample, "CHAR UTF-8", "CHAR UNICODE")))
+	_ = w.Close()
+	var tests = []struct {
+		name string
+		data []byte
+		want misc.EncodingHint
+	}{
+		{"ANSEL", []byte(ansel), misc.ANSEL},
+		{"UTF-16", utf16.Bytes(), misc.UTF16LE},
+		{"BOM", append([]byte("\xef\xbb\xbf"), sample...), misc.UTF8},
+		{"ANSI", []byte(strings.ReplaceAll(strings.ReplaceAll(sample, "CHAR UTF-8", "CHAR ANSI"), "ü", "\xfc")), misc.CP1252},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Read(bytes.NewReader(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if f.Encoding != tt.want {
+				t.Errorf("Encoding %s; want %s", f.Encoding, tt.want)
+			}
+			if name := f.Record("@I1@").PathValue("NAME"); name != "Anna /Müller/" {
+				t.Errorf("NAME = %q", name)
+			}
+		})
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	var tests = []string{
+		"0 HEAD\n2 VERS 5.5.1\n",
+		"0 HEAD\nX CHAR UTF-8\n",
+		"1 NAME orphan\n",
+		"0 HEAD\n1\n",
+	}
+	for _, tt := range tests {
+		if _, err := Read(strings.NewReader(tt)); err == nil {
+			t.Errorf("%q: expected an error", tt)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package gedcom
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/shofster/common/misc"
+)
+
+/*
+
+  File:    writer.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: Write a GEDCOM file.
+
+  Every Node is written as read, unknown tags included. A value with
+    new lines is written with CONT lines, and a line longer than
+    WriteOptions.LineLength is split with CONC lines (never next to a
+    space, as some readers trim them). GEDCOM 7.0 has no CONC, so a
+    HEAD with GEDC.VERS 7.x turns the splitting off.
+  HEAD.CHAR is written as the encoding being written.
+*/
+
+// DefaultLineLength is the longest line of GEDCOM 5.5.1.
+const DefaultLineLength = 255
+
+// WriteOptions controls how a file is written.
+type WriteOptions struct {
+	Encoding   misc.EncodingHint // UTF8 (the default), ANSEL, UTF16LE ...
+	LineLength int               // longest line before CONC. 0 = DefaultLineLength, < 0 = no limit
+	CRLF       bool              // end lines with CR LF
+	BOM        bool              // start with a byte order mark (UTF encodings only)
+}
+
+// charNames are the HEAD.CHAR values written for an encoding.
+var charNames = map[misc.EncodingHint]string{
+	misc.UTF8:      "UTF-8",
+	misc.ANSEL:     "ANSEL",
+	misc.UTF16LE:   "UNICODE",
+	misc.UTF16BE:   "UNICODE",
+	misc.CP1252:    "ANSI",
+	misc.IBM437:    "IBMPC",
+	misc.MACROMAN:  "MACINTOSH",
+	misc.ISO8859_1: "LATIN1",
+}
+
+// Writer writes records. Close writes the TRLR.
+type Writer struct {
+	w    io.WriteCloser
+	opts WriteOptions
+	err  error
+}
+
+// NewWriter writes records to w.
+//goland:noinspection GoUnusedExportedFunction
+func NewWriter(w io.Writer, opts WriteOptions) *Writer {
+	if opts.Encoding == misc.AUTO {
+		opts.Encoding = misc.UTF8
+	}
+	if opts.LineLength == 0 {
+		opts.LineLength = DefaultLineLength
+	}
+	return &Writer{w: misc.NewWriter(w, opts.Encoding, misc.WriteOptions{BOM: opts.BOM, CRLF: opts.CRLF}), opts: opts}
+}
+
+// Write a record and everything below it.
+func (w *Writer) Write(record *Node) error {
+	if w.err != nil {
+		return w.err
+	}
+	if record.Tag == "HEAD" && strings.HasPrefix(record.PathValue("GEDC", "VERS"), "7") {
+		w.opts.LineLength = -1
+	}
+	w.err = w.node(0, record, record.Tag == "HEAD")
+	return w.err
+}
+
+// Close writes the TRLR and flushes the encoder. The underlying writer is not closed.
+func (w *Writer) Close() error {
+	if w.err == nil {
+		w.err = w.line(0, "", "TRLR", "")
+	}
+	if err := w.w.Close(); w.err == nil {
+		w.err = err
+	}
+	return w.err
+}
+
+func (w *Writer) node(level int, n *Node, head bool) error {
+	value := n.Value
+	if head && level == 1 && n.Tag == "CHAR" {
+		if name, ok := charNames[w.opts.Encoding]; ok {
+			value = name
+		} else {
+			value = w.opts.Encoding.String()
+		}
+	}
+	lines := strings.Split(strings.ReplaceAll(value, "\r\n", "\n"), "\n")
+	if err := w.split(level, n.Xref, n.Tag, lines[0]); err != nil {
+		return err
+	}
+	for _, l := range lines[1:] {
+		if err := w.split(level+1, "", "CONT", l); err != nil {
+			return err
+		}
+	}
+	for _, c := range n.Children {
+		if err := w.node(level+1, c, head); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// split writes a line, continuing a long value with CONC lines.
+func (w *Writer) split(level int, xref, tag, value string) error {
+	for {
+		room := w.opts.LineLength - len(prefix(level, xref, tag))
+		text := []rune(value)
+		if w.opts.LineLength < 0 || len(text) <= room || room < 2 {
+			return w.line(level, xref, tag, value)
+		}
+		i := room
+		for i > 1 && (text[i-1] == ' ' || text[i] == ' ') {
+			i--
+		}
+		if i == 1 {
+			i = room
+		}
+		if err := w.line(level, xref, tag, string(text[:i])); err != nil {
+			return err
+		}
+		if tag != "CONC" {
+			level++
+		}
+		xref, tag, value = "", "CONC", string(text[i:])
+	}
+}
+
+func (w *Writer) line(level int, xref, tag, value string) error {
+	s := prefix(level, xref, tag)
+	if value != "" {
+		s += value
+	} else {
+		s = s[:len(s)-1]
+	}
+	_, err := io.WriteString(w.w, s+"\n")
+	if err != nil {
+		return errors.New(fmt.Sprintf("%s: %v", strings.TrimSpace(prefix(level, xref, tag)), err))
+	}
+	return nil
+}
+
+// prefix is "level [xref ]tag ".
+func prefix(level int, xref, tag string) string {
+	s := strconv.Itoa(level) + " "
+	if xref != "" {
+		s += xref + " "
+	}
+	return s + tag + " "
+}
+
+// Write every record (and the TRLR) to w.
+func (f *File) Write(w io.Writer, opts WriteOptions) error {
+	gw := NewWriter(w, opts)
+	for _, r := range f.Records {
+		if err := gw.Write(r); err != nil {
+			_ = gw.Close()
+			return err
+		}
+	}
+	return gw.Close()
+}
+
+// WriteFile writes every record to the file name.
+func (f *File) WriteFile(name string, opts WriteOptions) error {
+	file, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	err = f.Write(file, opts)
+	if e := file.Close(); err == nil {
+		err = e
+	}
+	return err
+}
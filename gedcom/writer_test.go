@@ -0,0 +1,85 @@
+package gedcom
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/shofster/common/misc"
+)
+
+/*
+
+  File:    writer_test.go
+  Author:  Bob Shofner
+
+*/
+
+func TestWriteRoundTrip(t *testing.T) {
+	f, err := Read(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = f.Write(&buf, WriteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != sample {
+		t.Errorf("wrote\n%s", buf.String())
+	}
+}
+
+func TestWriteANSEL(t *testing.T) {
+	f, _ := Read(strings.NewReader(sample))
+	var buf bytes.Buffer
+	if err := f.Write(&buf, WriteOptions{Encoding: misc.ANSEL, CRLF: true}); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.ReplaceAll(sample, "CHAR UTF-8", "CHAR ANSEL")
+	want = strings.ReplaceAll(want, "Müller", "M\xe8uller")
+	want = strings.ReplaceAll(want, "Köln", "K\xe8oln")
+	want = strings.ReplaceAll(want, "\n", "\r\n")
+	if buf.String() != want {
+		t.Errorf("wrote %q", buf.String())
+	}
+	again, err := Read(&buf)
+	if err != nil || again.Record("@I1@").PathValue("NAME") != "Anna /Müller/" {
+		t.Errorf("read back: %v", err)
+	}
+}
+
+func TestWriteCONC(t *testing.T) {
+	long := strings.TrimSpace(strings.Repeat("The quick brown fox jumps over the lazy dog. ", 20))
+	f := NewFile()
+	head := NewNode("HEAD", "")
+	head.Add("GEDC", "").Add("VERS", "5.5.1")
+	f.Add(head)
+	note := &Node{Xref: "@N1@", Tag: "NOTE", Value: long + "\nnext"}
+	f.Add(note)
+	var buf bytes.Buffer
+	if err := f.Write(&buf, WriteOptions{LineLength: 80}); err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range strings.Split(buf.String(), "\n") {
+		if len(l) > 80 {
+			t.Errorf("line of %d: %s", len(l), l)
+		}
+		if strings.HasPrefix(l, "1 CONC ") && (strings.HasPrefix(l[7:], " ") || strings.HasSuffix(l, " ")) {
+			t.Errorf("split at a space: %q", l)
+		}
+	}
+	again, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := again.Record("@N1@").Value; v != note.Value {
+		t.Errorf("read back %q", v)
+	}
+	// GEDCOM 7.0 has no CONC
+	head.Child("GEDC").Child("VERS").Value = "7.0"
+	buf.Reset()
+	_ = f.Write(&buf, WriteOptions{LineLength: 80})
+	if strings.Contains(buf.String(), "CONC") {
+		t.Error("CONC written for GEDCOM 7.0")
+	}
+}
@@ -0,0 +1,207 @@
+package misc
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+/*
+
+  File:    ansel.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: ANSEL (ANSI Z39.47) encoding, with the GEDCOM additions.
+
+  ASCII is unchanged. 0xA1 thru 0xCF are special letters and signs
+    (Ł, Ø, Æ, ß ...) and 0xE0 thru 0xFE are diacritics.
+  A diacritic comes BEFORE the letter it is on (E8 75 is ü) where
+    Unicode puts the combining mark after. Decoding reorders them and
+    composes (NFC); encoding decomposes (NFD) and reorders back.
+  Diacritics without a letter (the end of the text) are dropped.
+*/
+
+// anselSpecial are the runes of 0xA1 thru 0xCF. 0 is unassigned.
+var anselSpecial = [...]rune{
+	0x0141, 0x00D8, 0x0110, 0x00DE, 0x00C6, 0x0152, 0x02B9, 0x00B7, // A1 - A8
+	0x266D, 0x00AE, 0x00B1, 0x01A0, 0x01AF, 0x02BC, 0, 0x02BB, // A9 - B0
+	0x0142, 0x00F8, 0x0111, 0x00FE, 0x00E6, 0x0153, 0x02BA, 0x0131, // B1 - B8
+	0x00A3, 0x00F0, 0, 0x01A1, 0x01B0, 0x25A1, 0x25A0, 0x00B0, // B9 - C0
+	0x2113, 0x2117, 0x00A9, 0x266F, 0x00BF, 0x00A1, 0x00DF, 0x20AC, // C1 - C8
+	0, 0, 0, 0, 0x0065, 0x006F, 0x00DF, // C9 - CF
+}
+
+// anselMarks are the combining marks of 0xE0 thru 0xFE. 0 is unassigned.
+var anselMarks = [...]rune{
+	0x0309, 0x0300, 0x0301, 0x0302, 0x0303, 0x0304, 0x0306, 0x0307, // E0 - E7
+	0x0308, 0x030C, 0x030A, 0xFE20, 0xFE21, 0x0315, 0x030B, 0x0310, // E8 - EF
+	0x0327, 0x0328, 0x0323, 0x0324, 0x0325, 0x0333, 0x0332, 0x0326, // F0 - F7
+	0x031C, 0x032E, 0xFE22, 0xFE23, 0, 0, 0x0313, // F8 - FE
+}
+
+// anselEncode is the byte of every special rune and mark.
+var anselEncode = make(map[rune]byte)
+
+func init() {
+	for i, r := range anselSpecial {
+		if r > 0x7f {
+			anselEncode[r] = byte(0xA1 + i)
+		}
+	}
+	anselEncode[0x00DF] = 0xCF // GEDCOM, rather than MARC 21 C7
+	for i, r := range anselMarks {
+		if r != 0 {
+			anselEncode[r] = byte(0xE0 + i)
+		}
+	}
+	anselEncode[0x200D] = 0x8D // zero width joiner
+	anselEncode[0x200C] = 0x8E // zero width non-joiner
+}
+
+// anselEncoding implements encoding.Encoding.
+type anselEncoding struct{}
+
+func (anselEncoding) NewDecoder() *encoding.Decoder {
+	return &encoding.Decoder{Transformer: anselDecoder{}}
+}
+func (anselEncoding) NewEncoder() *encoding.Encoder {
+	return &encoding.Encoder{Transformer: anselEncoder{}}
+}
+
+// anselError is a rune ANSEL lacks. Replacement lets encoding.ReplaceUnsupported use '?'.
+type anselError rune
+
+func (e anselError) Error() string {
+	return fmt.Sprintf("ANSEL: unsupported %q", rune(e))
+}
+func (anselError) Replacement() byte {
+	return '?'
+}
+
+func anselRune(b byte) rune {
+	switch {
+	case b < 0x80:
+		return rune(b)
+	case b == 0x8D:
+		return 0x200D
+	case b == 0x8E:
+		return 0x200C
+	case b >= 0xA1 && b <= 0xCF && anselSpecial[b-0xA1] != 0:
+		return anselSpecial[b-0xA1]
+	}
+	return utf8.RuneError
+}
+
+func isAnselMark(b byte) bool {
+	return b >= 0xE0 && b <= 0xFE && anselMarks[b-0xE0] != 0
+}
+
+type anselDecoder struct{ transform.NopResetter }
+
+func (anselDecoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		end := nSrc
+		for end < len(src) && isAnselMark(src[end]) {
+			end++
+		}
+		if end == len(src) && !atEOF {
+			return nDst, nSrc, transform.ErrShortSrc
+		}
+		var s []byte
+		if end < len(src) {
+			s = utf8.AppendRune(s, anselRune(src[end]))
+			for i := nSrc; i < end; i++ {
+				s = utf8.AppendRune(s, anselMarks[src[i]-0xE0])
+			}
+			if end > nSrc {
+				s = norm.NFC.Bytes(s)
+			}
+			end++
+		}
+		if nDst+len(s) > len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		nDst += copy(dst[nDst:], s)
+		nSrc = end
+	}
+	return nDst, nSrc, nil
+}
+
+type anselEncoder struct{ transform.NopResetter }
+
+func (anselEncoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		if !atEOF && !utf8.FullRune(src[nSrc:]) {
+			return nDst, nSrc, transform.ErrShortSrc
+		}
+		r, size := utf8.DecodeRune(src[nSrc:])
+		if r < 0x80 && nSrc+1 == len(src) && !atEOF { // a mark may follow
+			return nDst, nSrc, transform.ErrShortSrc
+		}
+		if r < 0x80 && (nSrc+1 == len(src) || src[nSrc+1] < 0x80) { // plain ASCII
+			if nDst >= len(dst) {
+				return nDst, nSrc, transform.ErrShortDst
+			}
+			dst[nDst] = byte(r)
+			nDst++
+			nSrc++
+			continue
+		}
+		if r == utf8.RuneError && size == 1 {
+			return nDst, nSrc, encoding.ErrInvalidUTF8
+		}
+		// the letter and its marks, decomposed
+		end := nSrc + size
+		for end < len(src) {
+			if !atEOF && !utf8.FullRune(src[end:]) {
+				return nDst, nSrc, transform.ErrShortSrc
+			}
+			m, n := utf8.DecodeRune(src[end:])
+			if !unicode.Is(unicode.Mn, m) {
+				break
+			}
+			end += n
+		}
+		if end == len(src) && !atEOF {
+			return nDst, nSrc, transform.ErrShortSrc
+		}
+		runes := []rune(norm.NFD.String(string(src[nSrc:end])))
+		if unicode.Is(unicode.Mn, runes[0]) { // marks without a letter
+			nSrc = end
+			continue
+		}
+		out := make([]byte, 0, len(runes))
+		for _, m := range runes[1:] {
+			b, ok := anselEncode[m]
+			if !ok {
+				return nDst, nSrc, anselError(m)
+			}
+			out = append(out, b)
+		}
+		if b, ok := anselEncode[runes[0]]; ok {
+			out = append(out, b)
+		} else if runes[0] < 0x80 {
+			out = append(out, byte(runes[0]))
+		} else {
+			return nDst, nSrc, anselError(runes[0])
+		}
+		if nDst+len(out) > len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		nDst += copy(dst[nDst:], out)
+		nSrc = end
+	}
+	return nDst, nSrc, nil
+}
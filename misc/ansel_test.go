@@ -0,0 +1,65 @@
+package misc
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+/*
+
+  File:    ansel_test.go
+  Author:  Bob Shofner
+
+*/
+
+func TestANSEL(t *testing.T) {
+	var tests = []struct {
+		text  string
+		ansel string
+	}{
+		{"Smith", "Smith"},
+		{"Müller", "M\xe8uller"},
+		{"Łódź", "\xa1\xe2od\xe2z"},
+		{"Ærøskøbing", "\xa5r\xb2sk\xb2bing"},
+		{"Straße", "Stra\xcfe"},
+		{"Nguyễn", "Nguy\xe3\xe4en"},
+		{"© 1900", "\xc3 1900"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			text, err := ioutil.ReadAll(NewReader(bytes.NewReader([]byte(tt.ansel)), ANSEL))
+			if err != nil || string(text) != tt.text {
+				t.Errorf("decoded %q, %v; want %q", text, err, tt.text)
+			}
+			var buf bytes.Buffer
+			w := NewWriter(&buf, ANSEL, WriteOptions{})
+			_, err = w.Write([]byte(tt.text))
+			if e := w.Close(); err == nil {
+				err = e
+			}
+			if err != nil || buf.String() != tt.ansel {
+				t.Errorf("encoded %q, %v; want %q", buf.String(), err, tt.ansel)
+			}
+		})
+	}
+}
+
+func TestANSELUnsupported(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, ANSEL, WriteOptions{})
+	_, err := w.Write([]byte("Ж"))
+	if e := w.Close(); err == nil {
+		err = e
+	}
+	if err == nil {
+		t.Error("expected an error")
+	}
+	buf.Reset()
+	w = NewWriter(&buf, ANSEL, WriteOptions{Replace: true})
+	_, _ = w.Write([]byte("aЖé"))
+	_ = w.Close()
+	if buf.String() != "a?\xe2e" {
+		t.Errorf("replaced %q", buf.String())
+	}
+}
@@ -0,0 +1,411 @@
+package misc
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+/*
+
+  File:    bridge.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: Publish / Subscribe between processes.
+
+  A Bridge forwards messages submitted through it to the Publishers of
+    other processes sharing the same bridge name, over a local socket.
+  The first process to bind the socket is the hub and relays for the
+    others. When the hub goes away the rest reconnect and one of them
+    takes over.
+  Each frame is a 4 byte (big endian) length followed by JSON, so T must
+    be JSON encodable.
+  The socket is in a directory only the user can use ($XDG_RUNTIME_DIR,
+    else a 0700 directory in the temp directory), and a socket owned by
+    anybody else is neither dialed nor removed.
+  A process that stops reading is dropped after BridgeWriteTimeout, so it
+    holds up a Submit (or the hub's relay) no longer than that.
+*/
+
+// BridgeRetry is how long a bridge waits between connection attempts.
+var BridgeRetry = time.Second
+
+// BridgeWriteTimeout is how long a frame may take to write to another process.
+var BridgeWriteTimeout = time.Second
+
+// maxFrame guards against a corrupt length prefix.
+const maxFrame = 16 * 1024 * 1024
+
+// ErrBridgeClosed is returned by any call made after Close.
+var ErrBridgeClosed = errors.New("bridge is closed")
+
+// frame is what travels over the socket.
+type frame struct {
+	Topic string          `json:"topic"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// peer is one end of a socket connection. Writes are serialized.
+type peer struct {
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// write sends a frame, failing when it takes longer than timeout.
+func (p *peer) write(b []byte, timeout time.Duration) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.conn.SetWriteDeadline(time.Now().Add(timeout)); err != nil {
+		return err
+	}
+	return writeFrame(p.conn, b)
+}
+
+// Bridge joins a local Publisher to the Publishers of other processes.
+type Bridge[T any] struct {
+	path     string
+	retry    time.Duration
+	timeout  time.Duration // of a write
+	local    Publisher[T]
+	mu       sync.Mutex
+	peers    map[*peer]bool // hub: every client; client: the hub
+	listener net.Listener
+	hub      bool
+	closed   bool
+	quit     chan struct{}
+	done     chan struct{}
+}
+
+// BridgeSocket is the socket path used for a bridge name. "" if there is no private directory for it.
+//goland:noinspection GoUnusedExportedFunction
+func BridgeSocket(name string) string {
+	dir, err := socketDir()
+	if err != nil {
+		log.Println("bridge", name, err)
+		return ""
+	}
+	return filepath.Join(dir, name+".sock")
+}
+
+// socketDir is a directory for sockets that only this user can use.
+func socketDir() (string, error) {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" && filepath.IsAbs(dir) {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() && ownedByMe(info) {
+			return dir, nil
+		}
+	}
+	dir := filepath.Join(os.TempDir(), fmt.Sprintf("scsi-%d", os.Getuid()))
+	if err := os.Mkdir(dir, 0700); err != nil && !os.IsExist(err) {
+		return "", err
+	}
+	info, err := os.Lstat(dir) // not a link to elsewhere
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() || !ownedByMe(info) {
+		return "", errors.New(fmt.Sprintf("%s is not a directory of this user", dir))
+	}
+	if info.Mode().Perm() != 0700 {
+		if err = os.Chmod(dir, 0700); err != nil {
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+// foreignSocket reports whether path exists and belongs to another user.
+func foreignSocket(path string) bool {
+	info, err := os.Lstat(path)
+	return err == nil && !ownedByMe(info)
+}
+
+// NewBridge connects the local publisher to the other processes using name.
+//goland:noinspection GoUnusedExportedFunction
+func NewBridge[T any](local Publisher[T], name string) *Bridge[T] {
+	b := &Bridge[T]{
+		path:    BridgeSocket(name),
+		retry:   BridgeRetry,
+		timeout: BridgeWriteTimeout,
+		local:   local,
+		peers:   make(map[*peer]bool),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	if b.path == "" {
+		close(b.done) // local only
+		return b
+	}
+	go b.run()
+	return b
+}
+
+// Submit publishes m locally and in every connected process.
+func (b *Bridge[T]) Submit(m T) error {
+	return b.SubmitTopic("", m)
+}
+
+// SubmitTopic publishes m on topic locally and then in every connected process.
+// Remote delivery is best effort; only the local error is reported, and m is
+// not sent on when it could not be submitted locally.
+func (b *Bridge[T]) SubmitTopic(topic string, m T) error {
+	b.mu.Lock()
+	closed := b.closed
+	b.mu.Unlock()
+	if closed {
+		return ErrBridgeClosed
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	f, err := json.Marshal(frame{Topic: topic, Data: data})
+	if err != nil {
+		return err
+	}
+	if err = b.local.SubmitTopic(topic, m); err != nil {
+		return err
+	}
+	b.forward(f, nil)
+	return nil
+}
+
+// IsHub reports whether this process is relaying for the others.
+func (b *Bridge[T]) IsHub() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.hub
+}
+
+// Connected reports whether any other process is reachable.
+func (b *Bridge[T]) Connected() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.peers) > 0
+}
+
+// Close disconnects from the other processes. The local publisher is not closed.
+func (b *Bridge[T]) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrBridgeClosed
+	}
+	b.closed = true
+	close(b.quit)
+	if b.listener != nil {
+		_ = b.listener.Close()
+	}
+	for p := range b.peers {
+		_ = p.conn.Close()
+	}
+	b.mu.Unlock()
+	<-b.done
+	return nil
+}
+
+// run - connecting as a client, or else as the hub, until closed.
+func (b *Bridge[T]) run() {
+	defer close(b.done)
+	for {
+		if foreignSocket(b.path) {
+			log.Println("bridge", b.path, "belongs to another user")
+		} else if conn, err := dialLocal(b.path, b.retry); err == nil {
+			b.serveClient(conn)
+		} else if l, err := listenLocal(b.path); err == nil {
+			b.serveHub(l)
+		} else if isStale(b.path, b.retry) {
+			_ = os.Remove(b.path)
+			continue
+		}
+		select {
+		case <-b.quit:
+			return
+		case <-time.After(b.retry):
+		}
+	}
+}
+
+// serveClient reads from the hub until the connection drops.
+func (b *Bridge[T]) serveClient(conn net.Conn) {
+	p := &peer{conn: conn}
+	if !b.addPeer(p) {
+		return
+	}
+	b.read(p)
+}
+
+// serveHub accepts clients until closed, or until the socket file is taken over.
+func (b *Bridge[T]) serveHub(l net.Listener) {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		_ = l.Close()
+		return
+	}
+	b.listener = l
+	b.hub = true
+	b.mu.Unlock()
+	mine, _ := os.Stat(b.path)
+	var wg sync.WaitGroup
+	lost := make(chan struct{})
+	go func() { // watch the socket file
+		for {
+			select {
+			case <-b.quit:
+				return
+			case <-lost:
+				return
+			case <-time.After(b.retry):
+				if now, err := os.Stat(b.path); err != nil || mine == nil || !os.SameFile(mine, now) {
+					if ul, ok := l.(*net.UnixListener); ok {
+						ul.SetUnlinkOnClose(false) // the file belongs to the new hub
+					}
+					_ = l.Close()
+					return
+				}
+			}
+		}
+	}()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			break
+		}
+		p := &peer{conn: conn}
+		if !b.addPeer(p) {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.read(p)
+		}()
+	}
+	close(lost)
+	_ = l.Close()
+	b.mu.Lock()
+	b.listener = nil
+	b.hub = false
+	for p := range b.peers {
+		_ = p.conn.Close()
+	}
+	b.mu.Unlock()
+	wg.Wait()
+}
+
+func (b *Bridge[T]) addPeer(p *peer) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		_ = p.conn.Close()
+		return false
+	}
+	b.peers[p] = true
+	return true
+}
+
+// read delivers frames from p locally (and on to the other clients when the hub).
+func (b *Bridge[T]) read(p *peer) {
+	defer func() {
+		b.mu.Lock()
+		delete(b.peers, p)
+		b.mu.Unlock()
+		_ = p.conn.Close()
+	}()
+	r := bufio.NewReader(p.conn)
+	for {
+		raw, err := readFrame(r)
+		if err != nil {
+			return
+		}
+		var f frame
+		var m T
+		if json.Unmarshal(raw, &f) != nil || json.Unmarshal(f.Data, &m) != nil {
+			continue // not for us
+		}
+		if b.IsHub() {
+			b.forward(raw, p)
+		}
+		_ = b.local.SubmitTopic(f.Topic, m)
+	}
+}
+
+// forward writes a frame to every peer except from.
+func (b *Bridge[T]) forward(raw []byte, from *peer) {
+	b.mu.Lock()
+	peers := make([]*peer, 0, len(b.peers))
+	for p := range b.peers {
+		if p != from {
+			peers = append(peers, p)
+		}
+	}
+	b.mu.Unlock()
+	for _, p := range peers {
+		if err := p.write(raw, b.timeout); err != nil {
+			_ = p.conn.Close() // stalled or gone; the reader notices and cleans up
+		}
+	}
+}
+
+// listenLocal and dialLocal are the only places that know the transport.
+// AF_UNIX sockets are available on Linux, macOS and Windows 10 (1803) or later.
+func listenLocal(path string) (net.Listener, error) {
+	return net.Listen("unix", path)
+}
+func dialLocal(path string, timeout time.Duration) (net.Conn, error) {
+	return net.DialTimeout("unix", path, timeout)
+}
+
+// isStale reports whether path is a socket file nobody is listening on.
+func isStale(path string, timeout time.Duration) bool {
+	if _, err := os.Stat(path); err != nil {
+		return false
+	}
+	conn, err := dialLocal(path, timeout)
+	if err == nil {
+		_ = conn.Close()
+		return false
+	}
+	return true
+}
+
+func writeFrame(w io.Writer, b []byte) error {
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(b)))
+	if _, err := w.Write(size[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+func readFrame(r io.Reader) ([]byte, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n > maxFrame {
+		return nil, errors.New(fmt.Sprintf("frame of %d bytes is too large", n))
+	}
+	b := make([]byte, n)
+	_, err := io.ReadFull(r, b)
+	return b, err
+}
@@ -0,0 +1,126 @@
+package misc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+/*
+
+  File:    bridge_test.go
+  Author:  Bob Shofner
+
+*/
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for i := 0; i < 200; i++ {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("timeout waiting for %s", what)
+}
+
+func TestBridge(t *testing.T) {
+	BridgeRetry = 20 * time.Millisecond
+	name := fmt.Sprintf("bridge-test-%d", os.Getpid())
+	pa := NewPublisher[string](10)
+	pb := NewPublisher[string](10)
+	pc := NewPublisher[string](10)
+	ca, _ := pa.Subscribe(context.Background(), "file.*", SubscribeOptions{Buffer: 10})
+	cb, _ := pb.Subscribe(context.Background(), "file.*", SubscribeOptions{Buffer: 10})
+	cc, _ := pc.Subscribe(context.Background(), "file.*", SubscribeOptions{Buffer: 10})
+
+	ba := NewBridge[string](pa, name)
+	waitFor(t, "hub", ba.IsHub)
+	bb := NewBridge[string](pb, name)
+	waitFor(t, "client", bb.Connected)
+
+	_ = bb.SubmitTopic("file.saved", "b.txt")
+	receive(t, cb, "b.txt")
+	receive(t, ca, "b.txt")
+	_ = ba.SubmitTopic("file.saved", "a.txt")
+	receive(t, ca, "a.txt")
+	receive(t, cb, "a.txt")
+
+	// the hub goes away; b takes over.
+	_ = ba.Close()
+	waitFor(t, "new hub", bb.IsHub)
+	bc := NewBridge[string](pc, name)
+	waitFor(t, "reconnect", bc.Connected)
+	_ = bc.SubmitTopic("file.saved", "c.txt")
+	receive(t, cc, "c.txt")
+	receive(t, cb, "c.txt")
+
+	_ = bc.Close()
+	_ = bb.Close()
+	if err := bb.Submit("late"); err != ErrBridgeClosed {
+		t.Errorf("Submit after Close = %v; want %v", err, ErrBridgeClosed)
+	}
+}
+
+func TestBridgeSocket(t *testing.T) {
+	runtime := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", runtime)
+	if s := BridgeSocket("app"); s != filepath.Join(runtime, "app.sock") {
+		t.Errorf("BridgeSocket = %s; want it in XDG_RUNTIME_DIR", s)
+	}
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	dir, err := socketDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0700 {
+		t.Errorf("socket directory %s is %v; want 0700", dir, info.Mode())
+	}
+	if foreignSocket(filepath.Join(runtime, "none.sock")) || foreignSocket(runtime) {
+		t.Error("foreignSocket: want false for a missing or own file")
+	}
+}
+
+func TestBridgeStalledPeer(t *testing.T) {
+	defer func(d time.Duration) { BridgeWriteTimeout = d }(BridgeWriteTimeout)
+	BridgeRetry = 20 * time.Millisecond
+	BridgeWriteTimeout = 50 * time.Millisecond
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	name := fmt.Sprintf("bridge-stall-%d", os.Getpid())
+	pa := NewPublisher[string](10)
+	ca, _ := pa.Subscribe(context.Background(), AllTopics, SubscribeOptions{Buffer: 10})
+	ba := NewBridge[string](pa, name)
+	defer func() { _ = ba.Close() }()
+	waitFor(t, "hub", ba.IsHub)
+	conn, err := dialLocal(BridgeSocket(name), time.Second) // never reads
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	waitFor(t, "client", ba.Connected)
+	big := strings.Repeat("x", 1<<20)
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 5; i++ {
+			_ = ba.Submit(big)
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Submit blocked by a peer that does not read")
+	}
+	for i := 0; i < 5; i++ {
+		receive(t, ca, big)
+	}
+	waitFor(t, "stalled peer dropped", func() bool { return !ba.Connected() })
+}
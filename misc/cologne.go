@@ -0,0 +1,106 @@
+package misc
+
+import "strings"
+
+/*
+
+  File:    cologne.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: Kölner Phonetik (Cologne phonetics), Hans Joachim Postel 1969.
+
+  Each letter is a digit depending on its neighbours, repeated digits
+    are written once, then every 0 (vowel) but the first is dropped.
+    The code has no fixed length. Müller-Lüdenscheidt is 65752682.
+*/
+
+type cologne struct{}
+
+// NewCologne makes a Kölner Phonetik PhoneticEncoder.
+//goland:noinspection GoUnusedExportedFunction
+func NewCologne() PhoneticEncoder {
+	return cologne{}
+}
+
+func (cologne) Name() string {
+	return "cologne"
+}
+
+func (cologne) Encode(word string) []string {
+	s := letters(word, latinFold)
+	if s == "" {
+		return nil
+	}
+	var code []byte
+	for i := 0; i < len(s); i++ {
+		prev, next := charAt(s, i-1), charAt(s, i+1)
+		var d string
+		switch c := s[i]; c {
+		case 'A', 'E', 'I', 'J', 'O', 'U', 'Y':
+			d = "0"
+		case 'H':
+			continue
+		case 'B':
+			d = "1"
+		case 'P':
+			d = "1"
+			if next == 'H' {
+				d = "3"
+			}
+		case 'D', 'T':
+			d = "2"
+			if strings.IndexByte("CSZ", next) >= 0 {
+				d = "8"
+			}
+		case 'F', 'V', 'W':
+			d = "3"
+		case 'G', 'K', 'Q':
+			d = "4"
+		case 'C':
+			d = "8"
+			if i == 0 {
+				if strings.IndexByte("AHKLOQRUX", next) >= 0 {
+					d = "4"
+				}
+			} else if strings.IndexByte("AHKOQUX", next) >= 0 && strings.IndexByte("SZ", prev) < 0 {
+				d = "4"
+			}
+		case 'X':
+			d = "48"
+			if strings.IndexByte("CKQ", prev) >= 0 {
+				d = "8"
+			}
+		case 'L':
+			d = "5"
+		case 'M', 'N':
+			d = "6"
+		case 'R':
+			d = "7"
+		case 'S', 'Z':
+			d = "8"
+		}
+		for j := 0; j < len(d); j++ {
+			if len(code) == 0 || code[len(code)-1] != d[j] {
+				code = append(code, d[j])
+			}
+		}
+	}
+	if len(code) == 0 { // all H
+		return nil
+	}
+	out := code[:1]
+	for _, c := range code[1:] {
+		if c != '0' {
+			out = append(out, c)
+		}
+	}
+	return []string{string(out)}
+}
@@ -0,0 +1,232 @@
+package misc
+
+import (
+	"context"
+	"sync"
+)
+
+/*
+
+  File:    concurrent.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: Stack and Set that are safe for concurrent use.
+
+  Each wraps the plain type with a RWMutex. Snapshot copies the items
+    under the lock, so it can be ranged over while others keep changing
+    the original.
+  PopWait and TakeWait block until there is an item or ctx is done.
+    Waiters sleep on a channel that is closed (and replaced) whenever an
+    item is added.
+*/
+
+// waitList wakes everyone waiting for an addition.
+type waitList struct {
+	added chan struct{}
+}
+
+// wait returns the channel closed by the next broadcast. Call with the lock held.
+func (w *waitList) wait() <-chan struct{} {
+	if w.added == nil {
+		w.added = make(chan struct{})
+	}
+	return w.added
+}
+
+// broadcast wakes the waiters. Call with the lock held.
+func (w *waitList) broadcast() {
+	if w.added != nil {
+		close(w.added)
+		w.added = nil
+	}
+}
+
+type ConcurrentStack[T any] struct {
+	mu    sync.RWMutex
+	stack Stack[T]
+	waitList
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func NewConcurrentStack[T any]() *ConcurrentStack[T] {
+	return &ConcurrentStack[T]{}
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func (s *ConcurrentStack[T]) Count() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.stack.Count()
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func (s *ConcurrentStack[T]) Push(item T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stack.Push(item)
+	s.broadcast()
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func (s *ConcurrentStack[T]) Peek() (T, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.stack.Peek()
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func (s *ConcurrentStack[T]) Pop() (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stack.Pop()
+}
+
+// PopWait pops the top item, waiting for one if empty.
+// It returns ctx.Err() if ctx is done first.
+//goland:noinspection GoUnusedExportedFunction
+func (s *ConcurrentStack[T]) PopWait(ctx context.Context) (T, error) {
+	for {
+		s.mu.Lock()
+		item, ok := s.stack.Pop()
+		added := s.wait()
+		s.mu.Unlock()
+		if ok {
+			return item, nil
+		}
+		select {
+		case <-added:
+		case <-ctx.Done():
+			return item, ctx.Err()
+		}
+	}
+}
+
+// Snapshot copies the items, bottom to top.
+//goland:noinspection GoUnusedExportedFunction
+func (s *ConcurrentStack[T]) Snapshot() []T {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	items := make([]T, len(s.stack.items))
+	copy(items, s.stack.items)
+	return items
+}
+
+type ConcurrentSet[T comparable] struct {
+	mu  sync.RWMutex
+	set Set[T]
+	waitList
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func NewConcurrentSet[T comparable]() *ConcurrentSet[T] {
+	return &ConcurrentSet[T]{}
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func (s *ConcurrentSet[T]) Count() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.set.Count()
+}
+
+// Contains returns the index of t, or 0 if not in the set.
+// The index may be stale as soon as it is returned; prefer Has.
+//goland:noinspection GoUnusedExportedFunction
+func (s *ConcurrentSet[T]) Contains(t T) int {
+	s.mu.Lock() // may compact
+	defer s.mu.Unlock()
+	return s.set.Contains(t)
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func (s *ConcurrentSet[T]) Has(t T) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.set.Has(t)
+}
+
+// Add adds t, reporting whether it was new.
+//goland:noinspection GoUnusedExportedFunction
+func (s *ConcurrentSet[T]) Add(t T) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.set.Has(t) {
+		return false
+	}
+	s.set.Add(t)
+	s.broadcast()
+	return true
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func (s *ConcurrentSet[T]) Delete(t T) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.set.Delete(t)
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func (s *ConcurrentSet[T]) Get(ix int) (T, bool) {
+	s.mu.Lock() // may compact
+	defer s.mu.Unlock()
+	return s.set.Get(ix)
+}
+
+// Take removes and returns the first (oldest) item.
+//goland:noinspection GoUnusedExportedFunction
+func (s *ConcurrentSet[T]) Take() (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.take()
+}
+
+// TakeWait takes the first item, waiting for one if empty.
+// It returns ctx.Err() if ctx is done first.
+//goland:noinspection GoUnusedExportedFunction
+func (s *ConcurrentSet[T]) TakeWait(ctx context.Context) (T, error) {
+	for {
+		s.mu.Lock()
+		item, ok := s.take()
+		added := s.wait()
+		s.mu.Unlock()
+		if ok {
+			return item, nil
+		}
+		select {
+		case <-added:
+		case <-ctx.Done():
+			return item, ctx.Err()
+		}
+	}
+}
+
+// Snapshot copies the items, in order.
+//goland:noinspection GoUnusedExportedFunction
+func (s *ConcurrentSet[T]) Snapshot() []T {
+	s.mu.Lock() // Items compacts
+	defer s.mu.Unlock()
+	return s.set.Items()
+}
+
+// Each calls f with each item of a Snapshot until f returns false.
+// f may change the set.
+//goland:noinspection GoUnusedExportedFunction
+func (s *ConcurrentSet[T]) Each(f func(ix int, t T) bool) {
+	for ix, t := range s.Snapshot() {
+		if !f(ix+1, t) {
+			return
+		}
+	}
+}
+
+func (s *ConcurrentSet[T]) take() (T, bool) {
+	return s.set.takeFirst()
+}
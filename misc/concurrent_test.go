@@ -0,0 +1,152 @@
+package misc
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+/*
+
+  File:    concurrent_test.go
+  Author:  Bob Shofner
+
+  Run with -race.
+
+*/
+
+func TestConcurrentStack(t *testing.T) {
+	s := NewConcurrentStack[int]()
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				s.Push(w*100 + i)
+				_ = s.Snapshot()
+				_, _ = s.Peek()
+			}
+		}(w)
+	}
+	wg.Wait()
+	if n := s.Count(); n != 400 {
+		t.Errorf("Count = %d; want 400", n)
+	}
+	seen := make(map[int]bool)
+	for {
+		item, ok := s.Pop()
+		if !ok {
+			break
+		}
+		seen[item] = true
+	}
+	if len(seen) != 400 {
+		t.Errorf("popped %d distinct; want 400", len(seen))
+	}
+}
+
+func TestConcurrentStackPopWait(t *testing.T) {
+	s := NewConcurrentStack[string]()
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		s.Push("item 1")
+	}()
+	item, err := s.PopWait(context.Background())
+	if err != nil || item != "item 1" {
+		t.Errorf("PopWait = %s, %v; want item 1", item, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err = s.PopWait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("PopWait = %v; want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestConcurrentSet(t *testing.T) {
+	s := NewConcurrentSet[int]()
+	results := make(chan int, 100)
+	ctx, cancel := context.WithCancel(context.Background())
+	var consumers sync.WaitGroup
+	for c := 0; c < 3; c++ {
+		consumers.Add(1)
+		go func() {
+			defer consumers.Done()
+			for {
+				item, err := s.TakeWait(ctx)
+				if err != nil {
+					return
+				}
+				select {
+				case results <- item:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	var producers sync.WaitGroup
+	for p := 0; p < 4; p++ {
+		producers.Add(1)
+		go func(p int) {
+			defer producers.Done()
+			for i := 0; i < 50; i++ {
+				s.Add(p*50 + i)
+				s.Each(func(int, int) bool { return true })
+				_ = s.Has(i)
+			}
+		}(p)
+	}
+	producers.Wait()
+	seen := make(map[int]bool)
+	for len(seen) < 200 {
+		select {
+		case item := <-results:
+			if seen[item] {
+				t.Errorf("%d taken twice", item)
+			}
+			seen[item] = true
+		case <-time.After(time.Second):
+			t.Fatalf("took %d; want 200", len(seen))
+		}
+	}
+	cancel()
+	consumers.Wait()
+	if s.Count() != 0 {
+		t.Errorf("Count = %d; want 0", s.Count())
+	}
+}
+
+func TestConcurrentSetDrain(t *testing.T) {
+	s := NewConcurrentSet[int]()
+	const n = 10000
+	for i := 0; i < n; i++ {
+		s.Add(i)
+	}
+	s.Delete(5)
+	next := 0
+	for i := 0; i < n-1; i++ {
+		if i == n/2 {
+			s.Add(n) // added while draining
+		}
+		item, err := s.TakeWait(context.Background())
+		if next == 5 {
+			next++
+		}
+		if err != nil || item != next {
+			t.Fatalf("take %d = %d %v; want %d", i, item, err, next)
+		}
+		next++
+	}
+	if item, ok := s.Take(); !ok || item != n || s.Count() != 0 {
+		t.Errorf("last take = %d %v, Count %d; want %d", item, ok, s.Count(), n)
+	}
+	if _, ok := s.Take(); ok {
+		t.Error("take from empty set")
+	}
+	s.Add(0)
+	if ix := s.Contains(0); ix != 1 {
+		t.Errorf("Contains after drain = %d; want 1", ix)
+	}
+}
@@ -0,0 +1,140 @@
+package misc
+
+/*
+
+  File:    deque.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: generic double ended queue. A ring buffer that doubles
+    when full and halves when a quarter full. Index is front to back (1 relative).
+*/
+
+const minDeque = 8
+
+type Deque[T any] struct {
+	buf   []T
+	head  int // slot of the front item
+	count int
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func NewDeque[T any]() *Deque[T] {
+	return &Deque[T]{}
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func (d *Deque[T]) Count() int {
+	return d.count
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func (d *Deque[T]) PushBack(item T) {
+	d.grow()
+	d.buf[d.slot(d.count)] = item
+	d.count++
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func (d *Deque[T]) PushFront(item T) {
+	d.grow()
+	d.head = d.slot(len(d.buf) - 1)
+	d.buf[d.head] = item
+	d.count++
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func (d *Deque[T]) PopFront() (T, bool) {
+	var item T
+	if d.count == 0 {
+		return item, false
+	}
+	item, d.buf[d.head] = d.buf[d.head], item
+	d.head = d.slot(1)
+	d.count--
+	d.shrink()
+	return item, true
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func (d *Deque[T]) PopBack() (T, bool) {
+	var item T
+	if d.count == 0 {
+		return item, false
+	}
+	back := d.slot(d.count - 1)
+	item, d.buf[back] = d.buf[back], item
+	d.count--
+	d.shrink()
+	return item, true
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func (d *Deque[T]) PeekFront() (T, bool) {
+	return d.Get(1)
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func (d *Deque[T]) PeekBack() (T, bool) {
+	return d.Get(d.count)
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func (d *Deque[T]) Get(ix int) (t T, b bool) {
+	ix--
+	if ix > -1 && ix < d.count {
+		t = d.buf[d.slot(ix)]
+		b = true
+	}
+	return
+}
+
+// Clear removes every item.
+//goland:noinspection GoUnusedExportedFunction
+func (d *Deque[T]) Clear() {
+	d.buf = nil
+	d.head = 0
+	d.count = 0
+}
+
+// slot is the buffer position of the ix'th (0 relative) item.
+func (d *Deque[T]) slot(ix int) int {
+	return (d.head + ix) % len(d.buf)
+}
+
+func (d *Deque[T]) grow() {
+	if d.count == len(d.buf) {
+		size := len(d.buf) * 2
+		if size < minDeque {
+			size = minDeque
+		}
+		d.resize(size)
+	}
+}
+
+func (d *Deque[T]) shrink() {
+	if len(d.buf) > minDeque && d.count <= len(d.buf)/4 {
+		d.resize(len(d.buf) / 2)
+	}
+}
+
+func (d *Deque[T]) resize(size int) {
+	buf := make([]T, size)
+	if d.count > 0 {
+		if end := d.head + d.count; end <= len(d.buf) {
+			copy(buf, d.buf[d.head:end])
+		} else {
+			n := copy(buf, d.buf[d.head:])
+			copy(buf[n:], d.buf[:end-len(d.buf)])
+		}
+	}
+	d.buf = buf
+	d.head = 0
+}
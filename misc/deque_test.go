@@ -0,0 +1,56 @@
+package misc
+
+import "testing"
+
+/*
+
+  File:    deque_test.go
+  Author:  Bob Shofner
+
+*/
+
+func TestDeque(t *testing.T) {
+	d := NewDeque[int]()
+	// wrap around the ring and grow past the minimum
+	for i := 1; i <= 20; i++ {
+		if i%2 == 0 {
+			d.PushBack(i)
+		} else {
+			d.PushFront(i)
+		}
+	}
+	if n := d.Count(); n != 20 {
+		t.Errorf("Count = %d; want 20", n)
+	}
+	item, b := d.PeekFront()
+	if !b || item != 19 {
+		t.Errorf("peek front; 19 got = %d", item)
+	}
+	item, b = d.PeekBack()
+	if !b || item != 20 {
+		t.Errorf("peek back; 20 got = %d", item)
+	}
+	item, b = d.Get(10)
+	if !b || item != 1 {
+		t.Errorf("get 10; 1 got = %d", item)
+	}
+	for want := 19; want > 0; want -= 2 {
+		item, b = d.PopFront()
+		if !b || item != want {
+			t.Errorf("pop front; %d got = %d", want, item)
+		}
+	}
+	for want := 20; want > 0; want -= 2 {
+		item, b = d.PopBack()
+		if !b || item != want {
+			t.Errorf("pop back; %d got = %d", want, item)
+		}
+	}
+	item, b = d.PopBack()
+	if b || item != 0 {
+		t.Errorf("empty pop; got = %d", item)
+	}
+	if _, b = d.Get(1); b {
+		t.Error("get from empty Deque")
+	}
+}
@@ -0,0 +1,180 @@
+package misc
+
+import (
+	"sort"
+	"strings"
+)
+
+/*
+
+  File:    dmsoundex.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: Daitch-Mokotoff Soundex, Gary Mokotoff and Randy Daitch 1985.
+
+  Longest letter groups first, each is coded by where it is: at the start
+    of the name, before a vowel, or elsewhere. Some groups may be sounded
+    two ways (CH as in "Chaim" or "Charles") and the code branches; every
+    branch is returned. Codes are 6 digits, zero padded.
+  A code repeated by the next group is written once, unless a vowel (or
+    an uncoded letter) separates them.
+*/
+
+type daitchMokotoff struct{}
+
+// NewDaitchMokotoff makes a Daitch-Mokotoff Soundex PhoneticEncoder.
+//goland:noinspection GoUnusedExportedFunction
+func NewDaitchMokotoff() PhoneticEncoder {
+	return daitchMokotoff{}
+}
+
+func (daitchMokotoff) Name() string {
+	return "daitch-mokotoff"
+}
+
+// dmRule codes a letter group at the start, before a vowel, or elsewhere.
+// "" is not coded; "a|b" branches.
+type dmRule struct {
+	pattern string
+	start   string
+	vowel   string
+	other   string
+}
+
+var dmRules = []dmRule{
+	{"AI", "0", "1", ""}, {"AJ", "0", "1", ""}, {"AY", "0", "1", ""},
+	{"AU", "0", "7", ""},
+	{"A", "0", "", ""},
+	{"B", "7", "7", "7"},
+	{"CHS", "5", "54", "54"},
+	{"CH", "5|4", "5|4", "5|4"},
+	{"CK", "5|45", "5|45", "5|45"},
+	{"CSZ", "4", "4", "4"}, {"CZS", "4", "4", "4"},
+	{"CS", "4", "4", "4"}, {"CZ", "4", "4", "4"},
+	{"C", "5|4", "5|4", "5|4"},
+	{"DRZ", "4", "4", "4"}, {"DRS", "4", "4", "4"},
+	{"DSH", "4", "4", "4"}, {"DSZ", "4", "4", "4"}, {"DS", "4", "4", "4"},
+	{"DZH", "4", "4", "4"}, {"DZS", "4", "4", "4"}, {"DZ", "4", "4", "4"},
+	{"DT", "3", "3", "3"}, {"D", "3", "3", "3"},
+	{"EI", "0", "1", ""}, {"EJ", "0", "1", ""}, {"EY", "0", "1", ""},
+	{"EU", "1", "1", ""},
+	{"E", "0", "", ""},
+	{"FB", "7", "7", "7"}, {"F", "7", "7", "7"},
+	{"G", "5", "5", "5"},
+	{"H", "5", "5", ""},
+	{"IA", "1", "", ""}, {"IE", "1", "", ""}, {"IO", "1", "", ""}, {"IU", "1", "", ""},
+	{"I", "0", "", ""},
+	{"J", "1|4", "|4", "|4"},
+	{"KS", "5", "54", "54"},
+	{"KH", "5", "5", "5"}, {"K", "5", "5", "5"},
+	{"L", "8", "8", "8"},
+	{"MN", "66", "66", "66"}, {"M", "6", "6", "6"},
+	{"NM", "66", "66", "66"}, {"N", "6", "6", "6"},
+	{"OI", "0", "1", ""}, {"OJ", "0", "1", ""}, {"OY", "0", "1", ""},
+	{"O", "0", "", ""},
+	{"PF", "7", "7", "7"}, {"PH", "7", "7", "7"}, {"P", "7", "7", "7"},
+	{"Q", "5", "5", "5"},
+	{"RS", "94|4", "94|4", "94|4"}, {"RZ", "94|4", "94|4", "94|4"},
+	{"R", "9", "9", "9"},
+	{"SCHTSCH", "2", "4", "4"}, {"SCHTSH", "2", "4", "4"}, {"SCHTCH", "2", "4", "4"},
+	{"SCHT", "2", "43", "43"}, {"SCHD", "2", "43", "43"},
+	{"SCH", "4", "4", "4"},
+	{"SHTCH", "2", "4", "4"}, {"SHTSH", "2", "4", "4"}, {"SHCH", "2", "4", "4"},
+	{"SHT", "2", "43", "43"}, {"SHD", "2", "43", "43"},
+	{"SH", "4", "4", "4"},
+	{"STSCH", "2", "4", "4"}, {"STCH", "2", "4", "4"},
+	{"STRZ", "2", "4", "4"}, {"STRS", "2", "4", "4"}, {"STSH", "2", "4", "4"},
+	{"ST", "2", "43", "43"},
+	{"SZCZ", "2", "4", "4"}, {"SZCS", "2", "4", "4"},
+	{"SZT", "2", "43", "43"}, {"SZD", "2", "43", "43"},
+	{"SZ", "4", "4", "4"},
+	{"SC", "2", "4", "4"}, {"SD", "2", "43", "43"},
+	{"S", "4", "4", "4"},
+	{"TTSCH", "4", "4", "4"}, {"TTCH", "4", "4", "4"}, {"TCH", "4", "4", "4"},
+	{"TSCH", "4", "4", "4"}, {"TRZ", "4", "4", "4"}, {"TRS", "4", "4", "4"},
+	{"TTSZ", "4", "4", "4"}, {"TTS", "4", "4", "4"}, {"TTZ", "4", "4", "4"},
+	{"TSH", "4", "4", "4"}, {"TSZ", "4", "4", "4"}, {"TZS", "4", "4", "4"},
+	{"TS", "4", "4", "4"}, {"TC", "4", "4", "4"}, {"TZ", "4", "4", "4"},
+	{"TH", "3", "3", "3"}, {"T", "3", "3", "3"},
+	{"UI", "0", "1", ""}, {"UJ", "0", "1", ""}, {"UY", "0", "1", ""},
+	{"UE", "0", "", ""}, {"U", "0", "", ""},
+	{"V", "7", "7", "7"},
+	{"W", "7", "7", "7"},
+	{"X", "5", "54", "54"},
+	{"Y", "1", "", ""},
+	{"ZHDZH", "2", "4", "4"}, {"ZDZH", "2", "4", "4"}, {"ZDZ", "2", "4", "4"},
+	{"ZHD", "2", "43", "43"}, {"ZD", "2", "43", "43"},
+	{"ZSCH", "4", "4", "4"}, {"ZSH", "4", "4", "4"},
+	{"ZH", "4", "4", "4"}, {"ZS", "4", "4", "4"},
+	{"Z", "4", "4", "4"},
+}
+
+func init() { // longest pattern first, so the first match is the one to use
+	sort.SliceStable(dmRules, func(i, j int) bool { return len(dmRules[i].pattern) > len(dmRules[j].pattern) })
+}
+
+// dmBranch is one way of sounding the name so far.
+type dmBranch struct {
+	code string
+	last string // the previous group's code
+}
+
+func (daitchMokotoff) Encode(word string) []string {
+	s := letters(word, latinFold)
+	if s == "" {
+		return nil
+	}
+	branches := []dmBranch{{}}
+	for i := 0; i < len(s); {
+		rule := dmMatch(s, i)
+		code := rule.other
+		switch {
+		case i == 0:
+			code = rule.start
+		case isVowel(s, i+len(rule.pattern)):
+			code = rule.vowel
+		}
+		i += len(rule.pattern)
+		alternates := strings.Split(code, "|")
+		next := make([]dmBranch, 0, len(branches)*len(alternates))
+		for _, b := range branches {
+			for _, c := range alternates {
+				n := b
+				if !strings.HasSuffix(n.last, c) && len(n.code) < 6 {
+					n.code += c
+				}
+				n.last = c
+				next = append(next, n)
+			}
+		}
+		branches = next
+	}
+	codes := make([]string, 0, len(branches))
+	seen := make(map[string]bool)
+	for _, b := range branches {
+		c := (b.code + "000000")[:6]
+		if !seen[c] {
+			seen[c] = true
+			codes = append(codes, c)
+		}
+	}
+	return codes
+}
+
+// dmMatch finds the longest rule matching s at i.
+func dmMatch(s string, i int) dmRule {
+	for _, r := range dmRules {
+		if strings.HasPrefix(s[i:], r.pattern) {
+			return r
+		}
+	}
+	return dmRule{pattern: s[i : i+1]} // not reached; every letter has a rule
+}
@@ -0,0 +1,562 @@
+package misc
+
+import "strings"
+
+/*
+
+  File:    doublemetaphone.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: Double Metaphone, Lawrence Philips 2000.
+
+  Metaphone extended for names of Slavic, Germanic, Celtic, Greek,
+    French, Italian, Spanish and Chinese origin. Where a name may be
+    said two ways there is a primary and an alternate code (Smith is
+    SM0, XMT). Each is 4 characters at most.
+  Follows the published C++ of the algorithm rule for rule.
+*/
+
+type doubleMetaphone struct{}
+
+// NewDoubleMetaphone makes a Double Metaphone PhoneticEncoder.
+// Encode gives the primary code, then the alternate if different.
+//goland:noinspection GoUnusedExportedFunction
+func NewDoubleMetaphone() PhoneticEncoder {
+	return doubleMetaphone{}
+}
+
+func (doubleMetaphone) Name() string {
+	return "double-metaphone"
+}
+
+const dmMaxLen = 4
+
+// dmWord is a word being encoded.
+type dmWord struct {
+	s         string // upper case, padded with spaces
+	length    int
+	last      int
+	slavo     bool // Slavic or Germanic
+	primary   strings.Builder
+	alternate strings.Builder
+}
+
+// at reports whether any of subs is at start.
+func (w *dmWord) at(start int, subs ...string) bool {
+	if start < 0 {
+		return false
+	}
+	for _, sub := range subs {
+		if strings.HasPrefix(w.s[start:], sub) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *dmWord) char(i int) byte {
+	return charAt(w.s, i)
+}
+
+func (w *dmWord) vowel(i int) bool {
+	return strings.IndexByte("AEIOUY", w.char(i)) >= 0
+}
+
+func (w *dmWord) add(primary string, alternate ...string) {
+	w.primary.WriteString(primary)
+	if len(alternate) > 0 {
+		w.alternate.WriteString(alternate[0])
+	} else {
+		w.alternate.WriteString(primary)
+	}
+}
+
+func (doubleMetaphone) Encode(word string) []string {
+	var b strings.Builder
+	for _, r := range upperWord(word) {
+		if s, ok := latinFold[r]; ok {
+			b.WriteString(s)
+		} else if (r >= 'A' && r <= 'Z') || r == ' ' {
+			b.WriteRune(r)
+		}
+	}
+	s := strings.TrimSpace(b.String())
+	if s == "" {
+		return nil
+	}
+	w := &dmWord{s: s + "     ", length: len(s), last: len(s) - 1}
+	w.slavo = strings.Contains(s, "W") || strings.Contains(s, "K") ||
+		strings.Contains(s, "CZ") || strings.Contains(s, "WITZ")
+	current := 0
+	if w.at(0, "GN", "KN", "PN", "WR", "PS") {
+		current++
+	}
+	if w.char(0) == 'X' {
+		w.add("S")
+		current++
+	}
+	for (w.primary.Len() < dmMaxLen || w.alternate.Len() < dmMaxLen) && current < w.length {
+		current = w.encode(current)
+	}
+	primary, alternate := w.primary.String(), w.alternate.String()
+	if len(primary) > dmMaxLen {
+		primary = primary[:dmMaxLen]
+	}
+	if len(alternate) > dmMaxLen {
+		alternate = alternate[:dmMaxLen]
+	}
+	if alternate == primary {
+		return []string{primary}
+	}
+	return []string{primary, alternate}
+}
+
+// encode codes the letter(s) at current, returning the next position.
+func (w *dmWord) encode(current int) int {
+	next := w.char(current + 1)
+	switch w.char(current) {
+	case 'A', 'E', 'I', 'O', 'U', 'Y':
+		if current == 0 {
+			w.add("A")
+		}
+		return current + 1
+	case 'B':
+		w.add("P")
+		if next == 'B' {
+			return current + 2
+		}
+		return current + 1
+	case 'C':
+		return w.encodeC(current)
+	case 'D':
+		if w.at(current, "DG") {
+			if w.at(current+2, "I", "E", "Y") {
+				w.add("J") // edge
+				return current + 3
+			}
+			w.add("TK") // edgar
+			return current + 2
+		}
+		w.add("T")
+		if w.at(current, "DT", "DD") {
+			return current + 2
+		}
+		return current + 1
+	case 'F':
+		w.add("F")
+		if next == 'F' {
+			return current + 2
+		}
+		return current + 1
+	case 'G':
+		return w.encodeG(current)
+	case 'H':
+		// only keep if first & before vowel or between 2 vowels
+		if (current == 0 || w.vowel(current-1)) && w.vowel(current+1) {
+			w.add("H")
+			return current + 2
+		}
+		return current + 1
+	case 'J':
+		return w.encodeJ(current)
+	case 'K':
+		w.add("K")
+		if next == 'K' {
+			return current + 2
+		}
+		return current + 1
+	case 'L':
+		if next == 'L' {
+			// spanish e.g. "cabrillo", "gallegos"
+			if (current == w.length-3 && w.at(current-1, "ILLO", "ILLA", "ALLE")) ||
+				((w.at(w.last-1, "AS", "OS") || w.at(w.last, "A", "O")) && w.at(current-1, "ALLE")) {
+				w.add("L", "")
+				return current + 2
+			}
+			w.add("L")
+			return current + 2
+		}
+		w.add("L")
+		return current + 1
+	case 'M':
+		w.add("M")
+		if (w.at(current-1, "UMB") && (current+1 == w.last || w.at(current+2, "ER"))) || next == 'M' {
+			return current + 2 // dumb, thumb
+		}
+		return current + 1
+	case 'N':
+		w.add("N")
+		if next == 'N' {
+			return current + 2
+		}
+		return current + 1
+	case 'P':
+		if next == 'H' {
+			w.add("F")
+			return current + 2
+		}
+		w.add("P") // also account for "campbell", "raspberry"
+		if w.at(current+1, "P", "B") {
+			return current + 2
+		}
+		return current + 1
+	case 'Q':
+		w.add("K")
+		if next == 'Q' {
+			return current + 2
+		}
+		return current + 1
+	case 'R':
+		// french e.g. "rogier", but exclude "hochmeier"
+		if current == w.last && !w.slavo && w.at(current-2, "IE") && !w.at(current-4, "ME", "MA") {
+			w.add("", "R")
+		} else {
+			w.add("R")
+		}
+		if next == 'R' {
+			return current + 2
+		}
+		return current + 1
+	case 'S':
+		return w.encodeS(current)
+	case 'T':
+		return w.encodeT(current)
+	case 'V':
+		w.add("F")
+		if next == 'V' {
+			return current + 2
+		}
+		return current + 1
+	case 'W':
+		return w.encodeW(current)
+	case 'X':
+		// french e.g. breaux
+		if !(current == w.last && (w.at(current-3, "IAU", "EAU") || w.at(current-2, "AU", "OU"))) {
+			w.add("KS")
+		}
+		if w.at(current+1, "C", "X") {
+			return current + 2
+		}
+		return current + 1
+	case 'Z':
+		if next == 'H' { // chinese pinyin e.g. "zhao"
+			w.add("J")
+			return current + 2
+		}
+		if w.at(current+1, "ZO", "ZI", "ZA") || (w.slavo && current > 0 && w.char(current-1) != 'T') {
+			w.add("S", "TS")
+		} else {
+			w.add("S")
+		}
+		if next == 'Z' {
+			return current + 2
+		}
+		return current + 1
+	}
+	return current + 1
+}
+
+func (w *dmWord) encodeC(current int) int {
+	// various germanic
+	if current > 1 && !w.vowel(current-2) && w.at(current-1, "ACH") &&
+		w.char(current+2) != 'I' && (w.char(current+2) != 'E' || w.at(current-2, "BACHER", "MACHER")) {
+		w.add("K")
+		return current + 2
+	}
+	if current == 0 && w.at(current, "CAESAR") {
+		w.add("S")
+		return current + 2
+	}
+	if w.at(current, "CHIA") { // italian "chianti"
+		w.add("K")
+		return current + 2
+	}
+	if w.at(current, "CH") {
+		switch {
+		case current > 0 && w.at(current, "CHAE"): // "michael"
+			w.add("K", "X")
+		case current == 0 && (w.at(current+1, "HARAC", "HARIS") || w.at(current+1, "HOR", "HYM", "HIA", "HEM")) &&
+			!w.at(0, "CHORE"): // greek roots e.g. "chemistry", "chorus"
+			w.add("K")
+		case w.at(0, "VAN ", "VON ") || w.at(0, "SCH") || // germanic, greek, or otherwise "ch" for "kh" sound
+			w.at(current-2, "ORCHES", "ARCHIT", "ORCHID") || w.at(current+2, "T", "S") ||
+			((w.at(current-1, "A", "O", "U", "E") || current == 0) &&
+				w.at(current+2, "L", "R", "N", "M", "B", "H", "F", "V", "W", " ")):
+			w.add("K")
+		case current > 0:
+			if w.at(0, "MC") { // e.g. "McHugh"
+				w.add("K")
+			} else {
+				w.add("X", "K")
+			}
+		default:
+			w.add("X")
+		}
+		return current + 2
+	}
+	if w.at(current, "CZ") && !w.at(current-2, "WICZ") { // e.g. "czerny"
+		w.add("S", "X")
+		return current + 2
+	}
+	if w.at(current+1, "CIA") { // e.g. "focaccia"
+		w.add("X")
+		return current + 3
+	}
+	// double 'C', but not if e.g. "McClellan"
+	if w.at(current, "CC") && !(current == 1 && w.char(0) == 'M') {
+		// "bellocchio" but not "bacchus"
+		if w.at(current+2, "I", "E", "H") && !w.at(current+2, "HU") {
+			if (current == 1 && w.char(current-1) == 'A') || w.at(current-1, "UCCEE", "UCCES") {
+				w.add("KS") // "accident", "accede" "succeed"
+			} else {
+				w.add("X") // "bacci", "bertucci", other italian
+			}
+			return current + 3
+		}
+		w.add("K") // Pierce's rule
+		return current + 2
+	}
+	if w.at(current, "CK", "CG", "CQ") {
+		w.add("K")
+		return current + 2
+	}
+	if w.at(current, "CI", "CE", "CY") {
+		if w.at(current, "CIO", "CIE", "CIA") { // italian vs. english
+			w.add("S", "X")
+		} else {
+			w.add("S")
+		}
+		return current + 2
+	}
+	w.add("K")
+	switch { // name sent in "mac caffrey", "mac gregor"
+	case w.at(current+1, " C", " Q", " G"):
+		return current + 3
+	case w.at(current+1, "C", "K", "Q") && !w.at(current+1, "CE", "CI"):
+		return current + 2
+	}
+	return current + 1
+}
+
+func (w *dmWord) encodeG(current int) int {
+	next := w.char(current + 1)
+	if next == 'H' {
+		if current > 0 && !w.vowel(current-1) {
+			w.add("K")
+			return current + 2
+		}
+		if current == 0 { // "ghislane", "ghiradelli"
+			if w.char(current+2) == 'I' {
+				w.add("J")
+			} else {
+				w.add("K")
+			}
+			return current + 2
+		}
+		// Parker's rule (with some further refinements) e.g. "hugh"
+		if (current > 1 && w.at(current-2, "B", "H", "D")) || // e.g. "bough"
+			(current > 2 && w.at(current-3, "B", "H", "D")) || // e.g. "broughton"
+			(current > 3 && w.at(current-4, "B", "H")) {
+			return current + 2
+		}
+		// e.g. "laugh", "McLaughlin", "cough", "gough", "rough", "tough"
+		if current > 2 && w.char(current-1) == 'U' && w.at(current-3, "C", "G", "L", "R", "T") {
+			w.add("F")
+		} else if current > 0 && w.char(current-1) != 'I' {
+			w.add("K")
+		}
+		return current + 2
+	}
+	if next == 'N' {
+		switch {
+		case current == 1 && w.vowel(0) && !w.slavo:
+			w.add("KN", "N")
+		case !w.at(current+2, "EY") && next != 'Y' && !w.slavo: // not e.g. "cagney"
+			w.add("N", "KN")
+		default:
+			w.add("KN")
+		}
+		return current + 2
+	}
+	if w.at(current+1, "LI") && !w.slavo { // "tagliaro"
+		w.add("KL", "L")
+		return current + 2
+	}
+	// -ges-, -gep-, -gel-, -gie- at beginning
+	if current == 0 && (next == 'Y' ||
+		w.at(current+1, "ES", "EP", "EB", "EL", "EY", "IB", "IL", "IN", "IE", "EI", "ER")) {
+		w.add("K", "J")
+		return current + 2
+	}
+	// -ger-, -gy-
+	if (w.at(current+1, "ER") || next == 'Y') && !w.at(0, "DANGER", "RANGER", "MANGER") &&
+		!w.at(current-1, "E", "I") && !w.at(current-1, "RGY", "OGY") {
+		w.add("K", "J")
+		return current + 2
+	}
+	// italian e.g. "biaggi"
+	if w.at(current+1, "E", "I", "Y") || w.at(current-1, "AGGI", "OGGI") {
+		switch {
+		case w.at(0, "VAN ", "VON ") || w.at(0, "SCH") || w.at(current+1, "ET"): // obvious germanic
+			w.add("K")
+		case w.at(current+1, "IER "): // always soft if french ending
+			w.add("J")
+		default:
+			w.add("J", "K")
+		}
+		return current + 2
+	}
+	w.add("K")
+	if next == 'G' {
+		return current + 2
+	}
+	return current + 1
+}
+
+func (w *dmWord) encodeJ(current int) int {
+	// obvious spanish, "jose", "san jacinto"
+	if w.at(current, "JOSE") || w.at(0, "SAN ") {
+		if (current == 0 && w.char(current+4) == ' ') || w.at(0, "SAN ") {
+			w.add("H")
+		} else {
+			w.add("J", "H")
+		}
+		return current + 1
+	}
+	next := w.char(current + 1)
+	switch {
+	case current == 0 && !w.at(current, "JOSE"):
+		w.add("J", "A") // Yankelovich/Jankelowicz
+	case w.vowel(current-1) && !w.slavo && (next == 'A' || next == 'O'): // spanish pron. of e.g. "bajador"
+		w.add("J", "H")
+	case current == w.last:
+		w.add("J", "")
+	case !w.at(current+1, "L", "T", "K", "S", "N", "M", "B", "Z") && !w.at(current-1, "S", "K", "L"):
+		w.add("J")
+	}
+	if next == 'J' {
+		return current + 2
+	}
+	return current + 1
+}
+
+func (w *dmWord) encodeS(current int) int {
+	if w.at(current-1, "ISL", "YSL") { // special cases "island", "isle", "carlisle", "carlysle"
+		return current + 1
+	}
+	if current == 0 && w.at(current, "SUGAR") { // special case "sugar-"
+		w.add("X", "S")
+		return current + 1
+	}
+	if w.at(current, "SH") {
+		if w.at(current+1, "HEIM", "HOEK", "HOLM", "HOLZ") { // germanic
+			w.add("S")
+		} else {
+			w.add("X")
+		}
+		return current + 2
+	}
+	if w.at(current, "SIO", "SIA") || w.at(current, "SIAN") { // italian & armenian
+		if !w.slavo {
+			w.add("S", "X")
+		} else {
+			w.add("S")
+		}
+		return current + 3
+	}
+	// german & anglicisations, e.g. "smith" match "schmidt", "snider" match "schneider"
+	// also, -sz- in slavic language although in hungarian it is pronounced "s"
+	if (current == 0 && w.at(current+1, "M", "N", "L", "W")) || w.at(current+1, "Z") {
+		w.add("S", "X")
+		if w.at(current+1, "Z") {
+			return current + 2
+		}
+		return current + 1
+	}
+	if w.at(current, "SC") {
+		if w.char(current+2) == 'H' {
+			if w.at(current+3, "OO", "ER", "EN", "UY", "ED", "EM") { // dutch origin, e.g. "school", "schooner"
+				if w.at(current+3, "ER", "EN") { // "schermerhorn", "schenker"
+					w.add("X", "SK")
+				} else {
+					w.add("SK")
+				}
+				return current + 3
+			}
+			if current == 0 && !w.vowel(3) && w.char(3) != 'W' {
+				w.add("X", "S")
+			} else {
+				w.add("X")
+			}
+			return current + 3
+		}
+		if w.at(current+2, "I", "E", "Y") {
+			w.add("S")
+		} else {
+			w.add("SK")
+		}
+		return current + 3
+	}
+	if current == w.last && w.at(current-2, "AI", "OI") { // french e.g. "resnais", "artois"
+		w.add("", "S")
+	} else {
+		w.add("S")
+	}
+	if w.at(current+1, "S", "Z") {
+		return current + 2
+	}
+	return current + 1
+}
+
+func (w *dmWord) encodeT(current int) int {
+	if w.at(current, "TION") || w.at(current, "TIA", "TCH") {
+		w.add("X")
+		return current + 3
+	}
+	if w.at(current, "TH") || w.at(current, "TTH") {
+		if w.at(current+2, "OM", "AM") || w.at(0, "VAN ", "VON ") || w.at(0, "SCH") { // special case "thomas", "thames" or germanic
+			w.add("T")
+		} else {
+			w.add("0", "T")
+		}
+		return current + 2
+	}
+	w.add("T")
+	if w.at(current+1, "T", "D") {
+		return current + 2
+	}
+	return current + 1
+}
+
+func (w *dmWord) encodeW(current int) int {
+	if w.at(current, "WR") { // can also be in middle of word
+		w.add("R")
+		return current + 2
+	}
+	if current == 0 && (w.vowel(current+1) || w.at(current, "WH")) {
+		if w.vowel(current + 1) { // Wasserman should match Vasserman
+			w.add("A", "F")
+		} else {
+			w.add("A") // need Uomo to match Womo
+		}
+	}
+	// Arnow should match Arnoff
+	if (current == w.last && w.vowel(current-1)) || w.at(current-1, "EWSKI", "EWSKY", "OWSKI", "OWSKY") ||
+		w.at(0, "SCH") {
+		w.add("", "F")
+		return current + 1
+	}
+	if w.at(current, "WICZ", "WITZ") { // polish e.g. "filipowicz"
+		w.add("TS", "FX")
+		return current + 4
+	}
+	return current + 1
+}
@@ -0,0 +1,430 @@
+package misc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+/*
+
+  File:    durable.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: Durable (persistent) message queue. At least once delivery.
+
+  Every Submit is appended to a log file (one JSON record per line)
+    before it returns. Each message goes to one registered consumer
+    (round-robin) and stays in the log until the consumer calls Ack.
+    Messages not acknowledged when the process stops are delivered
+    again when the queue is next opened; Nack redelivers at once.
+  Acknowledged messages are removed from the log by Compact, which also
+    runs automatically after DurableOptions.CompactAfter acknowledgements
+    (a failure then is logged; the Ack itself has succeeded).
+  A torn last line (a crash while writing) is cut off on open; a corrupt
+    line anywhere else fails the open, leaving the log as it is.
+*/
+
+// ErrQueueClosed is returned by any call made after Close.
+var ErrQueueClosed = errors.New("queue is closed")
+
+// ErrUnknownDelivery is returned by Ack or Nack of an ID not pending.
+var ErrUnknownDelivery = errors.New("unknown delivery")
+
+// Delivery is a message handed to a consumer. It must be acknowledged.
+type Delivery[T any] struct {
+	ID      uint64
+	Data    T
+	Attempt int // deliveries of this message since the queue was opened
+}
+
+// DurableOptions configures a DurableQueue.
+type DurableOptions struct {
+	NoSync       bool // do not fsync after every write (faster, less durable)
+	CompactAfter int  // acknowledgements before an automatic Compact. 0 = 1000
+}
+
+// The DurableQueue interface describes the main entry points to DurableQueue(s).
+type DurableQueue[T any] interface {
+	// Register a new channel to receive deliveries
+	Register(chan<- Delivery[T]) error
+	// Unregister a channel so that it no longer receives deliveries.
+	Unregister(chan<- Delivery[T]) error
+	// Submit a new object. It is on disk when Submit returns nil.
+	Submit(T) error
+	// Ack acknowledges a delivery; it will not be delivered again.
+	Ack(id uint64) error
+	// Nack returns a delivery to the front of the queue.
+	Nack(id uint64) error
+	// Pending counts the messages not yet acknowledged.
+	Pending() int
+	// Compact rewrites the log without the acknowledged messages.
+	Compact() error
+	// Close this queue and every registered channel. Unacknowledged messages are kept.
+	Close() error
+}
+
+// record is one line in the log.
+type record struct {
+	Op   string          `json:"op"` // put, ack or seq
+	ID   uint64          `json:"id"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+type durableItem[T any] struct {
+	id       uint64
+	data     T
+	raw      json.RawMessage
+	attempt  int
+	inflight bool
+}
+
+type durableQueue[T any] struct {
+	mu        sync.Mutex
+	path      string
+	opts      DurableOptions
+	file      *os.File
+	lastID    uint64
+	items     map[uint64]*durableItem[T]
+	ready     []uint64
+	consumers []chan<- Delivery[T]
+	next      int
+	acked     int
+	closed    bool
+	wake      chan struct{}
+	quit      chan struct{}
+	done      chan struct{}
+}
+
+// DurableQueuePath is the log file used for a queue name in dir (normally Sys.StateDir).
+//goland:noinspection GoUnusedExportedFunction
+func DurableQueuePath(dir, name string) string {
+	return filepath.Join(dir, name+".queue")
+}
+
+// OpenDurableQueue opens (or creates) the queue name in dir (normally Sys.StateDir).
+//goland:noinspection GoUnusedExportedFunction
+func OpenDurableQueue[T any](dir, name string, opts DurableOptions) (DurableQueue[T], error) {
+	if opts.CompactAfter < 1 {
+		opts.CompactAfter = 1000
+	}
+	q := &durableQueue[T]{
+		path:  DurableQueuePath(dir, name),
+		opts:  opts,
+		items: make(map[uint64]*durableItem[T]),
+		wake:  make(chan struct{}, 1),
+		quit:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	if err := q.load(); err != nil {
+		return nil, err
+	}
+	go q.dispatch()
+	return q, nil
+}
+
+// load replays the log. A torn last line (crash while writing; no new line) is cut off.
+// Any other line that will not decode is an error, as the records after it must not be lost.
+func (q *durableQueue[T]) load() error {
+	f, err := os.OpenFile(q.path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	var good int64
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			break // a torn write, if anything
+		}
+		if err != nil {
+			_ = f.Close()
+			return err
+		}
+		if len(bytes.TrimSpace(line)) == 0 {
+			good += int64(len(line))
+			continue
+		}
+		var rec record
+		if err = json.Unmarshal(bytes.TrimSpace(line), &rec); err != nil {
+			_ = f.Close()
+			return errors.New(fmt.Sprintf("%s: corrupt record at offset %d: %v", q.path, good, err))
+		}
+		good += int64(len(line))
+		if rec.ID > q.lastID {
+			q.lastID = rec.ID
+		}
+		switch rec.Op {
+		case "put":
+			var m T
+			if err := json.Unmarshal(rec.Data, &m); err != nil {
+				_ = f.Close()
+				return errors.New(fmt.Sprintf("%s: message %d: %v", q.path, rec.ID, err))
+			}
+			q.items[rec.ID] = &durableItem[T]{id: rec.ID, data: m, raw: rec.Data}
+		case "ack":
+			if _, ok := q.items[rec.ID]; ok {
+				delete(q.items, rec.ID)
+				q.acked++
+			}
+		}
+	}
+	if err := f.Truncate(good); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if _, err := f.Seek(good, io.SeekStart); err != nil {
+		_ = f.Close()
+		return err
+	}
+	q.file = f
+	for id := range q.items {
+		q.ready = append(q.ready, id)
+	}
+	sort.Slice(q.ready, func(i, j int) bool { return q.ready[i] < q.ready[j] })
+	return nil
+}
+
+// write appends a record to the log.
+func (q *durableQueue[T]) write(rec record) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if _, err = q.file.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	if q.opts.NoSync {
+		return nil
+	}
+	return q.file.Sync()
+}
+
+// dispatch - hands ready messages to the consumers until closed.
+func (q *durableQueue[T]) dispatch() {
+	defer close(q.done)
+	for {
+		d, ch, ok := q.take()
+		if !ok {
+			select {
+			case <-q.wake:
+				continue
+			case <-q.quit:
+				return
+			}
+		}
+		for sent := false; !sent; {
+			select {
+			case ch <- d:
+				sent = true
+			case <-q.wake:
+				if !q.registered(ch) {
+					_ = q.Nack(d.ID)
+					sent = true
+				}
+			case <-q.quit:
+				return
+			}
+		}
+	}
+}
+
+// take picks the next ready message and the consumer to receive it.
+func (q *durableQueue[T]) take() (d Delivery[T], ch chan<- Delivery[T], ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed || len(q.ready) == 0 || len(q.consumers) == 0 {
+		return
+	}
+	item := q.items[q.ready[0]]
+	q.ready = q.ready[1:]
+	item.inflight = true
+	item.attempt++
+	ch = q.consumers[q.next%len(q.consumers)]
+	q.next = (q.next + 1) % len(q.consumers)
+	return Delivery[T]{ID: item.id, Data: item.data, Attempt: item.attempt}, ch, true
+}
+
+func (q *durableQueue[T]) registered(ch chan<- Delivery[T]) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, c := range q.consumers {
+		if c == ch {
+			return true
+		}
+	}
+	return false
+}
+
+func (q *durableQueue[T]) Register(ch chan<- Delivery[T]) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	q.consumers = append(q.consumers, ch)
+	signal(q.wake)
+	return nil
+}
+func (q *durableQueue[T]) Unregister(ch chan<- Delivery[T]) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	for i, c := range q.consumers {
+		if c == ch {
+			q.consumers = append(q.consumers[:i], q.consumers[i+1:]...)
+			break
+		}
+	}
+	signal(q.wake)
+	return nil
+}
+func (q *durableQueue[T]) Submit(m T) error {
+	raw, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	id := q.lastID + 1
+	if err = q.write(record{Op: "put", ID: id, Data: raw}); err != nil {
+		return err
+	}
+	q.lastID = id
+	q.items[id] = &durableItem[T]{id: id, data: m, raw: raw}
+	q.ready = append(q.ready, id)
+	signal(q.wake)
+	return nil
+}
+func (q *durableQueue[T]) Ack(id uint64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	item, ok := q.items[id]
+	if !ok || !item.inflight {
+		return ErrUnknownDelivery
+	}
+	if err := q.write(record{Op: "ack", ID: id}); err != nil {
+		return err
+	}
+	delete(q.items, id)
+	q.acked++
+	if q.acked >= q.opts.CompactAfter {
+		if err := q.compact(); err != nil { // the ack is in the log; compact again next time
+			log.Println("durable queue compact", q.path, err)
+		}
+	}
+	return nil
+}
+func (q *durableQueue[T]) Nack(id uint64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	item, ok := q.items[id]
+	if !ok || !item.inflight {
+		return ErrUnknownDelivery
+	}
+	item.inflight = false
+	q.ready = append([]uint64{id}, q.ready...)
+	signal(q.wake)
+	return nil
+}
+func (q *durableQueue[T]) Pending() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+func (q *durableQueue[T]) Compact() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	return q.compact()
+}
+
+// compact writes the pending messages to a new log and swaps it in.
+func (q *durableQueue[T]) compact() error {
+	ids := make([]uint64, 0, len(q.items))
+	for id := range q.items {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	tmp := q.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	err = enc.Encode(record{Op: "seq", ID: q.lastID}) // keep IDs unique after restart
+	for _, id := range ids {
+		if err == nil {
+			err = enc.Encode(record{Op: "put", ID: id, Data: q.items[id].raw})
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err = os.Rename(tmp, q.path); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	_ = q.file.Close()
+	q.file = f
+	q.acked = 0
+	return nil
+}
+
+func (q *durableQueue[T]) Close() error {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return ErrQueueClosed
+	}
+	q.closed = true
+	close(q.quit)
+	q.mu.Unlock()
+	<-q.done
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, ch := range q.consumers {
+		close(ch)
+	}
+	q.consumers = nil
+	return q.file.Close()
+}
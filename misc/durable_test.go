@@ -0,0 +1,142 @@
+package misc
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+/*
+
+  File:    durable_test.go
+  Author:  Bob Shofner
+
+*/
+
+func deliver(t *testing.T, ch <-chan Delivery[string], want string, attempt int) Delivery[string] {
+	t.Helper()
+	select {
+	case d := <-ch:
+		if d.Data != want || d.Attempt != attempt {
+			t.Errorf("received %s (attempt %d); want %s (attempt %d)", d.Data, d.Attempt, want, attempt)
+		}
+		return d
+	case <-time.After(time.Second):
+		t.Fatalf("timeout waiting for %s", want)
+	}
+	return Delivery[string]{}
+}
+
+func TestDurableQueue(t *testing.T) {
+	dir := t.TempDir()
+	q, err := OpenDurableQueue[string](dir, "jobs", DurableOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, job := range []string{"job 1", "job 2", "job 3"} {
+		if err = q.Submit(job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ch := make(chan Delivery[string])
+	_ = q.Register(ch)
+	d := deliver(t, ch, "job 1", 1)
+	_ = q.Ack(d.ID)
+	d = deliver(t, ch, "job 2", 1)
+	_ = q.Nack(d.ID)
+	d = deliver(t, ch, "job 2", 2) // not acknowledged before Close
+	if err = q.Ack(d.ID + 100); err != ErrUnknownDelivery {
+		t.Errorf("Ack unknown = %v; want %v", err, ErrUnknownDelivery)
+	}
+	_ = q.Close()
+	if _, ok := <-ch; ok {
+		t.Error("consumer channel not closed")
+	}
+	if err = q.Submit("job 4"); err != ErrQueueClosed {
+		t.Errorf("Submit after Close = %v; want %v", err, ErrQueueClosed)
+	}
+
+	// restart: the unacknowledged messages come back.
+	q, err = OpenDurableQueue[string](dir, "jobs", DurableOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := q.Pending(); n != 2 {
+		t.Errorf("Pending = %d; want 2", n)
+	}
+	ch = make(chan Delivery[string])
+	_ = q.Register(ch)
+	d2 := deliver(t, ch, "job 2", 1)
+	d3 := deliver(t, ch, "job 3", 1)
+	_ = q.Ack(d2.ID)
+	_ = q.Ack(d3.ID)
+	if err = q.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	_ = q.Submit("job 4")
+	d4 := deliver(t, ch, "job 4", 1)
+	if d4.ID != 4 {
+		t.Errorf("ID after Compact = %d; want 4", d4.ID)
+	}
+	_ = q.Close()
+
+	// a torn last line is ignored.
+	f, _ := os.OpenFile(DurableQueuePath(dir, "jobs"), os.O_APPEND|os.O_WRONLY, 0600)
+	_, _ = f.WriteString(`{"op":"put","id":5,"da`)
+	_ = f.Close()
+	q, err = OpenDurableQueue[string](dir, "jobs", DurableOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := q.Pending(); n != 1 {
+		t.Errorf("Pending = %d; want 1", n)
+	}
+	_ = q.Close()
+}
+
+func TestDurableQueueCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	path := DurableQueuePath(dir, "jobs")
+	log := `{"op":"put","id":1,"data":"job 1"}
+{"op":"put","id":2,"da
+{"op":"put","id":3,"data":"job 3"}
+`
+	if err := os.WriteFile(path, []byte(log), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenDurableQueue[string](dir, "jobs", DurableOptions{}); err == nil {
+		t.Error("corrupt middle line: want an error")
+	}
+	if b, _ := os.ReadFile(path); string(b) != log {
+		t.Error("the log was changed")
+	}
+}
+
+func TestDurableQueueAckCompactFails(t *testing.T) {
+	dir := t.TempDir()
+	q, err := OpenDurableQueue[string](dir, "jobs", DurableOptions{CompactAfter: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = q.Submit("job 1")
+	_ = q.Submit("job 2")
+	if err = os.Mkdir(DurableQueuePath(dir, "jobs")+".tmp", 0700); err != nil { // compact can not write
+		t.Fatal(err)
+	}
+	ch := make(chan Delivery[string])
+	_ = q.Register(ch)
+	d := deliver(t, ch, "job 1", 1)
+	if err = q.Ack(d.ID); err != nil {
+		t.Errorf("Ack = %v; want nil when only the compaction failed", err)
+	}
+	deliver(t, ch, "job 2", 1)
+	_ = q.Close()
+	q, err = OpenDurableQueue[string](dir, "jobs", DurableOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = q.Close() }()
+	if n := q.Pending(); n != 1 {
+		t.Errorf("Pending = %d after a restart; want 1 (job 1 was acknowledged)", n)
+	}
+}
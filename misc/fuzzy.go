@@ -0,0 +1,424 @@
+package misc
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+/*
+
+  File:    fuzzy.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: Fuzzy name matching.
+
+  Names are folded (FoldName) before comparing: accents are removed,
+    letters without a decomposition (Ł, Ø, ß, Æ) are spelled out, and
+    the rest upper cased with punctuation reduced to single spaces.
+  Edit similarity is one of Levenshtein, Damerau (optimal string
+    alignment) or Jaro-Winkler, scaled 0 (nothing alike) to 1 (same).
+  A NameIndex holds names under their phonetic codes. Match looks up
+    the names sharing a code with the one given (plus any with the same
+    folded spelling) and ranks them by a blend of the phonetic match
+    and the edit similarity - the "possible duplicates".
+*/
+
+// EditMeasure selects the edit similarity used by Similarity.
+type EditMeasure int
+
+const (
+	JaroWinklerMeasure EditMeasure = iota
+	LevenshteinMeasure
+	DamerauMeasure
+)
+
+func (m EditMeasure) String() string {
+	switch m {
+	case LevenshteinMeasure:
+		return "levenshtein"
+	case DamerauMeasure:
+		return "damerau"
+	}
+	return "jaro-winkler"
+}
+
+// stripMarks removes the combining marks left by decomposing (é as e + ´), or returns s
+// unchanged if it can not. A transform.Chain holds state, so each call makes its own.
+func stripMarks(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	if stripped, _, err := transform.String(t, s); err == nil {
+		return stripped
+	}
+	return s
+}
+
+// FoldName normalizes a name for comparing: "Łukasz  Müller-Öst" as "LUKASZ MULLER OST".
+//goland:noinspection GoUnusedExportedFunction
+func FoldName(name string) string {
+	s := stripMarks(name)
+	var b strings.Builder
+	space := false
+	for _, r := range upperWord(s) {
+		f, ok := latinFold[r]
+		switch {
+		case ok:
+		case r == '\'' || r == '’':
+			continue // O'Brien as OBRIEN
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			f = string(r)
+		default:
+			space = true
+			continue
+		}
+		if space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(f)
+		space = false
+	}
+	return b.String()
+}
+
+// Levenshtein is the number of single character insertions, deletions
+// or substitutions that change a into b.
+//goland:noinspection GoUnusedExportedFunction
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// Damerau is Levenshtein also counting the transposition of two adjacent
+// characters as one edit (optimal string alignment: no substring is edited twice).
+//goland:noinspection GoUnusedExportedFunction
+func Damerau(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = minInt(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
+
+// Jaro similarity of a and b, 0 thru 1.
+//goland:noinspection GoUnusedExportedFunction
+func Jaro(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+	window := maxInt(len(ra), len(rb))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+	ma := make([]bool, len(ra))
+	mb := make([]bool, len(rb))
+	matches := 0
+	for i := range ra {
+		lo, hi := maxInt(0, i-window), minInt(len(rb)-1, i+window)
+		for j := lo; j <= hi; j++ {
+			if !mb[j] && ra[i] == rb[j] {
+				ma[i], mb[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+	transpositions := 0
+	for i, j := 0, 0; i < len(ra); i++ {
+		if !ma[i] {
+			continue
+		}
+		for !mb[j] {
+			j++
+		}
+		if ra[i] != rb[j] {
+			transpositions++
+		}
+		j++
+	}
+	m := float64(matches)
+	return (m/float64(len(ra)) + m/float64(len(rb)) + (m-float64(transpositions/2))/m) / 3
+}
+
+// JaroWinkler is Jaro raised for a common prefix (up to 4 characters),
+// as names misspelled are usually right at the start.
+//goland:noinspection GoUnusedExportedFunction
+func JaroWinkler(a, b string) float64 {
+	j := Jaro(a, b)
+	ra, rb := []rune(a), []rune(b)
+	prefix := 0
+	for prefix < 4 && prefix < len(ra) && prefix < len(rb) && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+	return j + float64(prefix)*0.1*(1-j)
+}
+
+// Similarity of a and b by measure, 0 thru 1. The strings are compared as given (see FoldName).
+//goland:noinspection GoUnusedExportedFunction
+func Similarity(a, b string, measure EditMeasure) float64 {
+	if measure == JaroWinklerMeasure {
+		return JaroWinkler(a, b)
+	}
+	n := maxInt(len([]rune(a)), len([]rune(b)))
+	if n == 0 {
+		return 1
+	}
+	d := Levenshtein(a, b)
+	if measure == DamerauMeasure {
+		d = Damerau(a, b)
+	}
+	return 1 - float64(d)/float64(n)
+}
+
+// NameIndexOptions configures a NameIndex.
+type NameIndexOptions struct {
+	Encoder        PhoneticEncoder // nil = Double Metaphone
+	Measure        EditMeasure
+	PhoneticWeight float64 // share of the score for sounding alike, 0 thru 1. 0 = 0.3
+	MinScore       float64 // candidates scoring less are not returned. 0 = 0.7
+}
+
+// NameMatch is one candidate found by NameIndex.Match.
+type NameMatch struct {
+	ID       string
+	Name     string  // as added
+	Score    float64 // 0 thru 1
+	Phonetic bool    // shares a phonetic code
+}
+
+type nameEntry struct {
+	name   string
+	folded string
+	codes  []string
+}
+
+// NameIndex is an in-memory index of names, safe for concurrent use.
+type NameIndex struct {
+	mu     sync.RWMutex
+	opts   NameIndexOptions
+	names  map[string]*nameEntry // by ID
+	blocks map[string][]string   // IDs by phonetic code or folded name
+}
+
+// NewNameIndex creates an empty index.
+//goland:noinspection GoUnusedExportedFunction
+func NewNameIndex(opts NameIndexOptions) *NameIndex {
+	if opts.Encoder == nil {
+		opts.Encoder = NewDoubleMetaphone()
+	}
+	if opts.PhoneticWeight <= 0 || opts.PhoneticWeight > 1 {
+		opts.PhoneticWeight = 0.3
+	}
+	if opts.MinScore <= 0 {
+		opts.MinScore = 0.7
+	}
+	return &NameIndex{
+		opts:   opts,
+		names:  make(map[string]*nameEntry),
+		blocks: make(map[string][]string),
+	}
+}
+
+// Count of names in the index.
+func (x *NameIndex) Count() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.names)
+}
+
+// Add (or replace) the name of id.
+func (x *NameIndex) Add(id, name string) {
+	e := x.entry(name)
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.remove(id)
+	x.names[id] = e
+	for _, key := range e.keys() {
+		x.blocks[key] = append(x.blocks[key], id)
+	}
+}
+
+// Remove id. false if it is not in the index.
+func (x *NameIndex) Remove(id string) bool {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	return x.remove(id)
+}
+
+// Match ranks the names like name, best first. limit < 1 is no limit.
+func (x *NameIndex) Match(name string, limit int) []NameMatch {
+	return x.match(x.entry(name), "", limit)
+}
+
+// Similar ranks the names like that of id, other than id itself: its possible duplicates.
+func (x *NameIndex) Similar(id string, limit int) []NameMatch {
+	x.mu.RLock()
+	e, ok := x.names[id]
+	x.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	return x.match(e, id, limit)
+}
+
+func (x *NameIndex) match(e *nameEntry, skip string, limit int) []NameMatch {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	seen := map[string]bool{skip: true}
+	matches := make([]NameMatch, 0)
+	for _, key := range e.keys() {
+		for _, id := range x.blocks[key] {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			m := x.score(e, x.names[id])
+			if m.Score >= x.opts.MinScore {
+				m.ID = id
+				matches = append(matches, m)
+			}
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		if matches[i].Name != matches[j].Name {
+			return matches[i].Name < matches[j].Name
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// score blends sounding alike with the edit similarity of the folded names.
+func (x *NameIndex) score(a, b *nameEntry) NameMatch {
+	m := NameMatch{Name: b.name}
+	if a.folded == b.folded {
+		m.Score = 1
+		m.Phonetic = true
+		return m
+	}
+	for _, c := range a.codes {
+		for _, d := range b.codes {
+			if c == d {
+				m.Phonetic = true
+			}
+		}
+	}
+	w := x.opts.PhoneticWeight
+	m.Score = (1 - w) * Similarity(a.folded, b.folded, x.opts.Measure)
+	if m.Phonetic {
+		m.Score += w
+	}
+	return m
+}
+
+func (x *NameIndex) entry(name string) *nameEntry {
+	folded := FoldName(name)
+	return &nameEntry{name: name, folded: folded, codes: x.opts.Encoder.Encode(folded)}
+}
+
+func (x *NameIndex) remove(id string) bool {
+	e, ok := x.names[id]
+	if !ok {
+		return false
+	}
+	delete(x.names, id)
+	for _, key := range e.keys() {
+		ids := x.blocks[key]
+		for i, have := range ids {
+			if have == id {
+				ids = append(ids[:i], ids[i+1:]...)
+				break
+			}
+		}
+		if len(ids) == 0 {
+			delete(x.blocks, key)
+		} else {
+			x.blocks[key] = ids
+		}
+	}
+	return true
+}
+
+// keys are the blocks an entry is in: its phonetic codes and its folded name.
+func (e *nameEntry) keys() []string {
+	keys := make([]string, 0, len(e.codes)+1)
+	for _, c := range e.codes {
+		keys = append(keys, "#"+c)
+	}
+	return append(keys, "="+e.folded)
+}
+
+func minInt(n ...int) int {
+	m := n[0]
+	for _, v := range n[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
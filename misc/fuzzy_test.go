@@ -0,0 +1,152 @@
+package misc
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+)
+
+/*
+
+  File:    fuzzy_test.go
+  Author:  Bob Shofner
+
+*/
+
+func TestFoldName(t *testing.T) {
+	var tests = []struct {
+		name string
+		want string
+	}{
+		{"Müller", "MULLER"},
+		{"Łukasz  Müller-Öst", "LUKASZ MULLER OST"},
+		{"O'Brien", "OBRIEN"},
+		{"Straße", "STRASSE"},
+		{"Søren Ærø", "SOREN AERO"},
+		{"  José  ", "JOSE"},
+		{"Dvořák", "DVORAK"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FoldName(tt.name); got != tt.want {
+				t.Errorf("Expected %s: got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	var tests = []struct {
+		a, b        string
+		levenshtein int
+		damerau     int
+	}{
+		{"", "", 0, 0},
+		{"KITTEN", "SITTING", 3, 3},
+		{"CA", "AC", 2, 1},
+		{"SMITH", "SMIHT", 2, 1},
+		{"CA", "ABC", 3, 3}, // OSA: no edit of the transposed pair
+		{"MÜLLER", "MULLER", 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s,%s", tt.a, tt.b), func(t *testing.T) {
+			if d := Levenshtein(tt.a, tt.b); d != tt.levenshtein {
+				t.Errorf("Levenshtein = %d; want %d", d, tt.levenshtein)
+			}
+			if d := Damerau(tt.a, tt.b); d != tt.damerau {
+				t.Errorf("Damerau = %d; want %d", d, tt.damerau)
+			}
+		})
+	}
+}
+
+func TestJaroWinkler(t *testing.T) {
+	var tests = []struct {
+		a, b string
+		want float64
+	}{
+		{"MARTHA", "MARHTA", 0.961},
+		{"DWAYNE", "DUANE", 0.840},
+		{"DIXON", "DICKSONX", 0.813},
+		{"SMITH", "SMITH", 1},
+		{"ABC", "XYZ", 0},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s,%s", tt.a, tt.b), func(t *testing.T) {
+			if got := JaroWinkler(tt.a, tt.b); math.Abs(got-tt.want) > 0.001 {
+				t.Errorf("Expected %.3f: got %.3f", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNameIndex(t *testing.T) {
+	x := NewNameIndex(NameIndexOptions{})
+	x.Add("1", "Schmidt")
+	x.Add("2", "Smith")
+	x.Add("3", "Schmitt")
+	x.Add("4", "Jones")
+	x.Add("5", "Müller")
+	x.Add("6", "Mueller")
+	if n := x.Count(); n != 6 {
+		t.Errorf("Count = %d; want 6", n)
+	}
+	m := x.Match("Smyth", 0)
+	if len(m) == 0 || m[0].ID != "2" || !m[0].Phonetic {
+		t.Fatalf("Match(Smyth) = %v; want Smith first", m)
+	}
+	for _, c := range m {
+		if c.ID == "4" {
+			t.Errorf("Match(Smyth) includes Jones")
+		}
+	}
+	m = x.Match("MULLER", 1)
+	if len(m) != 1 || m[0].ID != "5" || m[0].Score != 1 {
+		t.Errorf("Match(MULLER) = %v; want Müller scoring 1", m)
+	}
+	m = x.Similar("5", 0)
+	if len(m) == 0 || m[0].ID != "6" {
+		t.Errorf("Similar(5) = %v; want Mueller", m)
+	}
+	for i := 1; i < len(m); i++ {
+		if m[i].Score > m[i-1].Score {
+			t.Errorf("Similar(5) not ranked: %v", m)
+		}
+	}
+	if !x.Remove("2") || x.Remove("2") {
+		t.Error("Remove(2) should succeed once")
+	}
+	for _, c := range x.Match("Smyth", 0) {
+		if c.ID == "2" {
+			t.Error("Match(Smyth) found removed Smith")
+		}
+	}
+	x.Add("3", "Jonas")
+	if m = x.Match("Schmitt", 0); len(m) > 0 && m[0].ID == "3" && m[0].Score == 1 {
+		t.Error("Add did not replace the name of 3")
+	}
+}
+
+func TestNameIndexConcurrent(t *testing.T) {
+	x := NewNameIndex(NameIndexOptions{})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				x.Add(fmt.Sprint(i, "-", j), "Łukasz Müller-Öst")
+				if f := FoldName("Zoë Brontë"); f != "ZOE BRONTE" {
+					t.Errorf("FoldName = %s", f)
+					return
+				}
+				x.Match("Lukas Mueller", 3)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if x.Count() != 400 {
+		t.Errorf("Count = %d; want 400", x.Count())
+	}
+}
@@ -0,0 +1,271 @@
+package misc
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+
+  File:    instance.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: Only one running instance of an application.
+
+  The first instance creates the lock file name.lock (normally in
+    Sys.StateDir) holding its process id and the path of a local socket
+    it listens on. A later instance finds the lock, sends its arguments
+    (and working directory, to resolve relative file names) over the
+    socket, and gets ErrAlreadyRunning; it should then exit.
+  A lock left by a crash is stale: its process is gone, or nobody
+    answers on its socket. A stale lock is removed and taken over.
+    An empty lock is stale only once it is InstanceWait old.
+  The frames on the socket are those of the Bridge.
+*/
+
+// ErrAlreadyRunning is returned by AcquireInstance when another instance has the lock.
+// The arguments were given to it.
+var ErrAlreadyRunning = errors.New("another instance is running")
+
+// InstanceWait is how long AcquireInstance waits for an instance that is starting up.
+var InstanceWait = 2 * time.Second
+
+// InstanceArgs are the arguments of a later instance.
+type InstanceArgs struct {
+	Args []string `json:"args"`
+	Dir  string   `json:"dir"` // the working directory
+}
+
+// instanceLock is the content of the lock file.
+type instanceLock struct {
+	PID    int    `json:"pid"`
+	Socket string `json:"socket"`
+}
+
+// Instance holds the lock until Close.
+type Instance struct {
+	lockPath string
+	lock     instanceLock
+	listener net.Listener
+	onArgs   func(InstanceArgs)
+	mu       sync.Mutex
+	closed   bool
+	conns    sync.WaitGroup
+}
+
+// InstanceLockPath is the lock file used for the application name in dir.
+//goland:noinspection GoUnusedExportedFunction
+func InstanceLockPath(dir, name string) string {
+	return filepath.Join(dir, name+".lock")
+}
+
+// AcquireInstance makes this process the instance of name, calling onArgs (on its own
+// goroutine) with the arguments of every later instance. If another instance has the
+// lock, args are sent to it and ErrAlreadyRunning returned.
+//goland:noinspection GoUnusedExportedFunction
+func AcquireInstance(dir, name string, args []string, onArgs func(InstanceArgs)) (*Instance, error) {
+	in := &Instance{lockPath: InstanceLockPath(dir, name), onArgs: onArgs}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	wd, _ := os.Getwd()
+	for tries := 0; tries < 3; tries++ {
+		f, err := os.OpenFile(in.lockPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			socket, err := instanceSocket(dir, name)
+			if err == nil {
+				err = in.serve(f, socket)
+			}
+			if e := f.Close(); err == nil {
+				err = e
+			}
+			if err != nil {
+				_ = os.Remove(in.lockPath)
+				return nil, err
+			}
+			return in, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		lock, err := readInstanceLock(in.lockPath)
+		if err == nil {
+			if err = forwardArgs(lock, InstanceArgs{Args: args, Dir: wd}); err == nil {
+				return nil, ErrAlreadyRunning
+			}
+		}
+		log.Println("instance", in.lockPath, "is stale:", err)
+		removeStaleLock(in.lockPath, lock)
+	}
+	return nil, errors.New(fmt.Sprintf("unable to lock %s", in.lockPath))
+}
+
+// serve listens on socket and writes the lock file f.
+func (in *Instance) serve(f *os.File, socket string) error {
+	if _, err := os.Stat(socket); err == nil {
+		_ = os.Remove(socket) // left by a crash; the lock is ours
+	}
+	l, err := listenLocal(socket)
+	if err != nil {
+		return err
+	}
+	in.listener = l
+	in.lock = instanceLock{PID: os.Getpid(), Socket: socket}
+	b, err := json.Marshal(in.lock)
+	if err == nil {
+		_, err = f.Write(b)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if err != nil {
+		_ = l.Close()
+		return err
+	}
+	go in.accept()
+	return nil
+}
+
+// accept reads the arguments of later instances until Close.
+func (in *Instance) accept() {
+	for {
+		conn, err := in.listener.Accept()
+		if err != nil {
+			return
+		}
+		in.conns.Add(1)
+		go func() {
+			defer in.conns.Done()
+			defer func() { _ = conn.Close() }()
+			_ = conn.SetDeadline(time.Now().Add(InstanceWait))
+			raw, err := readFrame(bufio.NewReader(conn))
+			if err != nil {
+				return
+			}
+			var args InstanceArgs
+			if err = json.Unmarshal(raw, &args); err != nil {
+				return
+			}
+			_ = writeFrame(conn, []byte("ok"))
+			if in.onArgs != nil {
+				in.onArgs(args)
+			}
+		}()
+	}
+}
+
+// Close stops listening and removes the lock.
+func (in *Instance) Close() error {
+	in.mu.Lock()
+	if in.closed {
+		in.mu.Unlock()
+		return nil
+	}
+	in.closed = true
+	in.mu.Unlock()
+	err := in.listener.Close()
+	in.conns.Wait()
+	if e := os.Remove(in.lockPath); err == nil && !os.IsNotExist(e) {
+		err = e
+	}
+	return err
+}
+
+// instanceSocket is the socket of name: in dir if the path is short enough
+// for a unix socket (about 100 bytes), else in the private directory of the Bridge sockets.
+func instanceSocket(dir, name string) (string, error) {
+	if socket := filepath.Join(dir, name+".sock"); len(socket) < 100 {
+		return socket, nil
+	}
+	sockets, err := socketDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(sockets, name+".instance.sock"), nil
+}
+
+// readInstanceLock reads a lock file, waiting for an instance still writing it.
+func readInstanceLock(path string) (lock instanceLock, err error) {
+	deadline := time.Now().Add(InstanceWait)
+	for {
+		var b []byte
+		if b, err = ioutil.ReadFile(path); err == nil {
+			if err = json.Unmarshal(b, &lock); err == nil {
+				if lock.PID < 1 || !processAlive(lock.PID) {
+					return lock, errors.New(fmt.Sprintf("process %d has ended", lock.PID))
+				}
+				return lock, nil
+			}
+			if strings.TrimSpace(string(b)) != "" {
+				return lock, err // not a lock file
+			}
+		}
+		if time.Now().After(deadline) {
+			return lock, errors.New("the lock is empty")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// forwardArgs sends args to the instance of lock, waiting for its answer.
+func forwardArgs(lock instanceLock, args InstanceArgs) error {
+	b, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	conn, err := dialLocal(lock.Socket, InstanceWait)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(InstanceWait))
+	if err = writeFrame(conn, b); err != nil {
+		return err
+	}
+	_, err = readFrame(bufio.NewReader(conn))
+	return err
+}
+
+// removeStaleLock removes the lock file, unless another instance has just taken it over.
+// An empty (or unreadable) lock is left alone until it is InstanceWait old; its
+// instance may be starting up.
+func removeStaleLock(path string, stale instanceLock) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return
+	}
+	var lock instanceLock
+	if err = json.Unmarshal(b, &lock); err != nil {
+		if time.Since(info.ModTime()) < InstanceWait {
+			return
+		}
+	} else if lock != stale {
+		return
+	}
+	_ = os.Remove(path)
+	if lock.Socket != "" {
+		_ = os.Remove(lock.Socket)
+	}
+}
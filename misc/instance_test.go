@@ -0,0 +1,74 @@
+package misc
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+/*
+
+  File:    instance_test.go
+  Author:  Bob Shofner
+
+*/
+
+func TestInstance(t *testing.T) {
+	dir := t.TempDir()
+	heard := make(chan InstanceArgs, 1)
+	first, err := AcquireInstance(dir, "app", nil, func(args InstanceArgs) { heard <- args })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = AcquireInstance(dir, "app", []string{"a.ged"}, nil); err != ErrAlreadyRunning {
+		t.Fatalf("second instance: %v; want ErrAlreadyRunning", err)
+	}
+	args := <-heard
+	wd, _ := os.Getwd()
+	if fmt.Sprint(args.Args) != "[a.ged]" || args.Dir != wd {
+		t.Errorf("forwarded %+v", args)
+	}
+	if err = first.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(InstanceLockPath(dir, "app")); !os.IsNotExist(err) {
+		t.Error("lock not removed by Close")
+	}
+}
+
+func TestInstanceStale(t *testing.T) {
+	dir := t.TempDir()
+	socket, err := instanceSocket(dir, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock := fmt.Sprintf(`{"pid": %d, "socket": "%s"}`, 0x7ffffff0, socket)
+	if err = os.WriteFile(InstanceLockPath(dir, "app"), []byte(lock), 0600); err != nil {
+		t.Fatal(err)
+	}
+	in, err := AcquireInstance(dir, "app", nil, nil)
+	if err != nil {
+		t.Fatal("stale lock:", err)
+	}
+	_ = in.Close()
+}
+
+func TestInstanceEmptyLock(t *testing.T) {
+	path := InstanceLockPath(t.TempDir(), "app")
+	if err := os.WriteFile(path, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	removeStaleLock(path, instanceLock{})
+	if _, err := os.Stat(path); err != nil {
+		t.Fatal("a new empty lock was removed")
+	}
+	old := time.Now().Add(-2 * InstanceWait)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+	removeStaleLock(path, instanceLock{})
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("an old empty lock was kept")
+	}
+}
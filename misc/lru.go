@@ -0,0 +1,229 @@
+package misc
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+/*
+
+  File:    lru.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: generic least recently used cache. Safe for concurrent use.
+
+  Entries are evicted, least recently used first, when there are more
+    than MaxEntries or their total size is more than MaxSize. SizeOf
+    measures an entry (e.g. the bytes of a decoded image); without it
+    each entry has size 1.
+  With a TTL an entry expires that long after it was Put. Expired
+    entries are dropped when found, or all at once by RemoveExpired.
+  OnEvict is called (outside the cache lock) for every entry removed,
+    the old value of a key Put again included, except by Remove and Clear.
+*/
+
+// LRUOptions configures a LRUCache. A zero limit is no limit.
+type LRUOptions[K comparable, V any] struct {
+	MaxEntries int
+	MaxSize    int64
+	SizeOf     func(V) int64
+	TTL        time.Duration
+	OnEvict    func(K, V)
+}
+
+type lruEntry[K comparable, V any] struct {
+	key     K
+	value   V
+	size    int64
+	expires time.Time
+}
+
+type LRUCache[K comparable, V any] struct {
+	mu    sync.Mutex
+	opts  LRUOptions[K, V]
+	order *list.List // front is most recently used
+	items map[K]*list.Element
+	size  int64
+	now   func() time.Time
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func NewLRUCache[K comparable, V any](opts LRUOptions[K, V]) *LRUCache[K, V] {
+	return &LRUCache[K, V]{
+		opts:  opts,
+		order: list.New(),
+		items: make(map[K]*list.Element),
+		now:   time.Now,
+	}
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func (c *LRUCache[K, V]) Count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items)
+}
+
+// Size is the total size of the entries.
+//goland:noinspection GoUnusedExportedFunction
+func (c *LRUCache[K, V]) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+// Get returns the value of key, marking it recently used.
+//goland:noinspection GoUnusedExportedFunction
+func (c *LRUCache[K, V]) Get(key K) (V, bool) {
+	var value V
+	c.mu.Lock()
+	e, ok := c.items[key]
+	if !ok {
+		c.mu.Unlock()
+		return value, false
+	}
+	entry := e.Value.(*lruEntry[K, V])
+	if c.expired(entry) {
+		c.remove(e)
+		c.mu.Unlock()
+		c.evicted([]*lruEntry[K, V]{entry})
+		return value, false
+	}
+	c.order.MoveToFront(e)
+	c.mu.Unlock()
+	return entry.value, true
+}
+
+// Peek returns the value of key without marking it used.
+//goland:noinspection GoUnusedExportedFunction
+func (c *LRUCache[K, V]) Peek(key K) (V, bool) {
+	var value V
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[key]; ok {
+		if entry := e.Value.(*lruEntry[K, V]); !c.expired(entry) {
+			return entry.value, true
+		}
+	}
+	return value, false
+}
+
+// Put adds or replaces key, then evicts to fit the limits.
+// A value larger than MaxSize by itself is not cached; the old value of key is
+// dropped, and the rest of the cache is left as it was.
+//goland:noinspection GoUnusedExportedFunction
+func (c *LRUCache[K, V]) Put(key K, value V) {
+	entry := &lruEntry[K, V]{key: key, value: value, size: 1}
+	if c.opts.SizeOf != nil {
+		entry.size = c.opts.SizeOf(value)
+	}
+	c.mu.Lock()
+	var evicted []*lruEntry[K, V]
+	if e, ok := c.items[key]; ok {
+		evicted = append(evicted, e.Value.(*lruEntry[K, V]))
+		c.remove(e)
+	}
+	if c.opts.MaxSize > 0 && entry.size > c.opts.MaxSize {
+		c.mu.Unlock()
+		c.evicted(evicted)
+		return
+	}
+	if c.opts.TTL > 0 {
+		entry.expires = c.now().Add(c.opts.TTL)
+	}
+	c.items[key] = c.order.PushFront(entry)
+	c.size += entry.size
+	for c.over() {
+		e := c.order.Back()
+		evicted = append(evicted, e.Value.(*lruEntry[K, V]))
+		c.remove(e)
+	}
+	c.mu.Unlock()
+	c.evicted(evicted)
+}
+
+// Remove drops key, reporting whether it was cached.
+//goland:noinspection GoUnusedExportedFunction
+func (c *LRUCache[K, V]) Remove(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[key]
+	if ok {
+		c.remove(e)
+	}
+	return ok
+}
+
+// RemoveExpired drops every expired entry.
+//goland:noinspection GoUnusedExportedFunction
+func (c *LRUCache[K, V]) RemoveExpired() {
+	c.mu.Lock()
+	var evicted []*lruEntry[K, V]
+	for e := c.order.Front(); e != nil; {
+		next := e.Next()
+		if entry := e.Value.(*lruEntry[K, V]); c.expired(entry) {
+			evicted = append(evicted, entry)
+			c.remove(e)
+		}
+		e = next
+	}
+	c.mu.Unlock()
+	c.evicted(evicted)
+}
+
+// Keys returns the keys, most recently used first.
+//goland:noinspection GoUnusedExportedFunction
+func (c *LRUCache[K, V]) Keys() []K {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	keys := make([]K, 0, len(c.items))
+	for e := c.order.Front(); e != nil; e = e.Next() {
+		keys = append(keys, e.Value.(*lruEntry[K, V]).key)
+	}
+	return keys
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func (c *LRUCache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	c.items = make(map[K]*list.Element)
+	c.size = 0
+}
+
+func (c *LRUCache[K, V]) over() bool {
+	if c.order.Len() == 0 {
+		return false
+	}
+	return (c.opts.MaxEntries > 0 && c.order.Len() > c.opts.MaxEntries) ||
+		(c.opts.MaxSize > 0 && c.size > c.opts.MaxSize)
+}
+
+func (c *LRUCache[K, V]) expired(entry *lruEntry[K, V]) bool {
+	return !entry.expires.IsZero() && !c.now().Before(entry.expires)
+}
+
+func (c *LRUCache[K, V]) remove(e *list.Element) {
+	entry := c.order.Remove(e).(*lruEntry[K, V])
+	delete(c.items, entry.key)
+	c.size -= entry.size
+}
+
+func (c *LRUCache[K, V]) evicted(entries []*lruEntry[K, V]) {
+	if c.opts.OnEvict == nil {
+		return
+	}
+	for _, entry := range entries {
+		c.opts.OnEvict(entry.key, entry.value)
+	}
+}
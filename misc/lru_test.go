@@ -0,0 +1,91 @@
+package misc
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+/*
+
+  File:    lru_test.go
+  Author:  Bob Shofner
+
+*/
+
+func TestLRUCache(t *testing.T) {
+	var evicted []string
+	c := NewLRUCache[string, int](LRUOptions[string, int]{
+		MaxEntries: 3,
+		OnEvict:    func(k string, _ int) { evicted = append(evicted, k) },
+	})
+	c.Put("a", 1)
+	c.Put("b", 2)
+	c.Put("c", 3)
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Errorf("get a; 1 got = %d", v)
+	}
+	c.Put("d", 4) // b is least recently used
+	if _, ok := c.Get("b"); ok {
+		t.Error("b wasn't evicted")
+	}
+	if got := fmt.Sprint(c.Keys()); got != "[d a c]" {
+		t.Errorf("Keys = %s; want [d a c]", got)
+	}
+	if got := fmt.Sprint(evicted); got != "[b]" {
+		t.Errorf("evicted = %s; want [b]", got)
+	}
+	if !c.Remove("a") || c.Count() != 2 {
+		t.Error("Remove a")
+	}
+}
+
+func TestLRUCacheSize(t *testing.T) {
+	var evicted []string
+	c := NewLRUCache[string, []byte](LRUOptions[string, []byte]{
+		MaxSize: 10,
+		SizeOf:  func(b []byte) int64 { return int64(len(b)) },
+		OnEvict: func(k string, _ []byte) { evicted = append(evicted, k) },
+	})
+	c.Put("a", make([]byte, 4))
+	c.Put("b", make([]byte, 4))
+	c.Put("c", make([]byte, 4))
+	if got := fmt.Sprint(c.Keys()); got != "[c b]" || c.Size() != 8 {
+		t.Errorf("Keys = %s, Size = %d; want [c b], 8", got, c.Size())
+	}
+	c.Put("big", make([]byte, 11))
+	if got := fmt.Sprint(c.Keys()); got != "[c b]" || c.Size() != 8 || fmt.Sprint(evicted) != "[a]" {
+		t.Errorf("after an oversize value Keys = %s, Size = %d, evicted %v; want [c b], 8, [a]", got, c.Size(), evicted)
+	}
+	c.Put("b", make([]byte, 11)) // the old b is not kept
+	if _, ok := c.Get("b"); ok {
+		t.Error("stale b cached after an oversize Put")
+	}
+	if got := fmt.Sprint(c.Keys()); got != "[c]" || c.Size() != 4 || fmt.Sprint(evicted) != "[a b]" {
+		t.Errorf("after an oversize b Keys = %s, Size = %d, evicted %v; want [c], 4, [a b]", got, c.Size(), evicted)
+	}
+	c.Put("c", make([]byte, 2))
+	if c.Size() != 2 || fmt.Sprint(evicted) != "[a b c]" {
+		t.Errorf("after replacing c Size = %d, evicted %v; want 2, [a b c]", c.Size(), evicted)
+	}
+}
+
+func TestLRUCacheTTL(t *testing.T) {
+	now := time.Now()
+	c := NewLRUCache[string, int](LRUOptions[string, int]{TTL: time.Minute})
+	c.now = func() time.Time { return now }
+	c.Put("a", 1)
+	now = now.Add(30 * time.Second)
+	c.Put("b", 2)
+	if _, ok := c.Get("a"); !ok {
+		t.Error("a expired early")
+	}
+	now = now.Add(45 * time.Second)
+	if _, ok := c.Peek("a"); ok {
+		t.Error("a didn't expire")
+	}
+	c.RemoveExpired()
+	if got := fmt.Sprint(c.Keys()); got != "[b]" {
+		t.Errorf("Keys = %s; want [b]", got)
+	}
+}
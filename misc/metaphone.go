@@ -0,0 +1,171 @@
+package misc
+
+import "strings"
+
+/*
+
+  File:    metaphone.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: Metaphone, Lawrence Philips 1990.
+
+  English pronunciation rules reduce a word to 16 consonant sounds;
+    0 is "th" and X is "sh". Vowels are kept only at the start.
+    The code is cut to 4 characters (0 = no limit), as is usual.
+*/
+
+type metaphone struct {
+	maxLen int
+}
+
+// NewMetaphone makes a Metaphone PhoneticEncoder, codes up to 4 characters.
+//goland:noinspection GoUnusedExportedFunction
+func NewMetaphone() PhoneticEncoder {
+	return metaphone{maxLen: 4}
+}
+
+func (metaphone) Name() string {
+	return "metaphone"
+}
+
+const frontVowels = "EIY"
+const varson = "CSPTG" // these before H make one sound
+
+func (m metaphone) Encode(word string) []string {
+	s := letters(word, latinFold)
+	if s == "" {
+		return nil
+	}
+	// initial letters
+	switch next := charAt(s, 1); s[0] {
+	case 'K', 'G', 'P':
+		if next == 'N' {
+			s = s[1:]
+		}
+	case 'A':
+		if next == 'E' {
+			s = s[1:]
+		}
+	case 'W':
+		if next == 'R' {
+			s = s[1:]
+		} else if next == 'H' {
+			s = "W" + s[2:]
+		}
+	case 'X':
+		s = "S" + s[1:]
+	}
+	var b strings.Builder
+	last := len(s) - 1
+	at := func(i int, sub string) bool { return strings.HasPrefix(s[i:], sub) }
+	for n := 0; n < len(s) && (m.maxLen == 0 || b.Len() < m.maxLen); n++ {
+		c := s[n]
+		prev, next := charAt(s, n-1), charAt(s, n+1)
+		if c != 'C' && prev == c {
+			continue // doubled letter
+		}
+		switch c {
+		case 'A', 'E', 'I', 'O', 'U':
+			if n == 0 {
+				b.WriteByte(c)
+			}
+		case 'B':
+			if !(prev == 'M' && n == last) { // silent in "mb"
+				b.WriteByte('B')
+			}
+		case 'C':
+			switch {
+			case prev == 'S' && n < last && strings.IndexByte(frontVowels, next) >= 0:
+				// silent in sci, sce, scy
+			case at(n, "CIA"):
+				b.WriteByte('X')
+			case n < last && strings.IndexByte(frontVowels, next) >= 0:
+				b.WriteByte('S')
+			case prev == 'S' && next == 'H':
+				b.WriteByte('K')
+			case next == 'H':
+				if n == 0 && len(s) >= 3 && isVowel(s, 2) {
+					b.WriteByte('K')
+				} else {
+					b.WriteByte('X')
+				}
+			default:
+				b.WriteByte('K')
+			}
+		case 'D':
+			if n+1 < last && next == 'G' && strings.IndexByte(frontVowels, s[n+2]) >= 0 {
+				b.WriteByte('J')
+				n += 2
+			} else {
+				b.WriteByte('T')
+			}
+		case 'G':
+			switch {
+			case n+1 == last && next == 'H':
+			case n+1 < last && next == 'H' && !isVowel(s, n+2):
+			case n > 0 && (at(n, "GN") || at(n, "GNED")):
+			case n < last && strings.IndexByte(frontVowels, next) >= 0 && prev != 'G':
+				b.WriteByte('J')
+			default:
+				b.WriteByte('K')
+			}
+		case 'H':
+			if n < last && !(n > 0 && strings.IndexByte(varson, prev) >= 0) && isVowel(s, n+1) {
+				b.WriteByte('H')
+			}
+		case 'K':
+			if prev != 'C' {
+				b.WriteByte('K')
+			}
+		case 'P':
+			if next == 'H' {
+				b.WriteByte('F')
+			} else {
+				b.WriteByte('P')
+			}
+		case 'Q':
+			b.WriteByte('K')
+		case 'S':
+			if at(n, "SH") || at(n, "SIO") || at(n, "SIA") {
+				b.WriteByte('X')
+			} else {
+				b.WriteByte('S')
+			}
+		case 'T':
+			switch {
+			case at(n, "TIA"), at(n, "TIO"):
+				b.WriteByte('X')
+			case at(n, "TCH"):
+			case next == 'H':
+				b.WriteByte('0')
+			default:
+				b.WriteByte('T')
+			}
+		case 'V':
+			b.WriteByte('F')
+		case 'W', 'Y':
+			if isVowel(s, n+1) {
+				b.WriteByte(c)
+			}
+		case 'X':
+			b.WriteString("KS")
+		case 'Z':
+			b.WriteByte('S')
+		default: // F J L M N R
+			b.WriteByte(c)
+		}
+	}
+	code := b.String()
+	if m.maxLen > 0 && len(code) > m.maxLen {
+		code = code[:m.maxLen]
+	}
+	return []string{code}
+}
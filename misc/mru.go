@@ -0,0 +1,239 @@
+package misc
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+/*
+
+  File:    mru.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: generic most recently used list. Safe for concurrent use.
+
+  Touch moves an item to the front (adding it if new); items past the
+    capacity fall off the end. Index is most recent first (1 relative).
+  A list made by OpenMRUList is kept in a JSON file (normally under
+    Sys.StateDir) and saved after every change.
+  Listeners are called, outside the lock, with the items after every change.
+*/
+
+type MRUList[T comparable] struct {
+	mu        sync.Mutex
+	items     []T
+	capacity  int
+	path      string // "" = not saved
+	listeners []func([]T)
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func NewMRUList[T comparable](capacity int) *MRUList[T] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &MRUList[T]{capacity: capacity}
+}
+
+// MRUListPath is the file used for a list name in dir (normally Sys.StateDir).
+//goland:noinspection GoUnusedExportedFunction
+func MRUListPath(dir, name string) string {
+	return filepath.Join(dir, name+".mru.json")
+}
+
+// OpenMRUList loads the list name from dir (if saved before), and saves it there after every change.
+//goland:noinspection GoUnusedExportedFunction
+func OpenMRUList[T comparable](dir, name string, capacity int) (*MRUList[T], error) {
+	m := NewMRUList[T](capacity)
+	m.path = MRUListPath(dir, name)
+	if err := m.Load(m.path); err != nil && !os.IsNotExist(err) {
+		return m, err
+	}
+	return m, nil
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func (m *MRUList[T]) Count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.items)
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func (m *MRUList[T]) Capacity() int {
+	return m.capacity
+}
+
+// Touch makes t the most recent item.
+//goland:noinspection GoUnusedExportedFunction
+func (m *MRUList[T]) Touch(t T) {
+	m.mu.Lock()
+	if len(m.items) > 0 && m.items[0] == t {
+		m.mu.Unlock()
+		return
+	}
+	m.remove(t)
+	m.items = append(m.items, t)
+	copy(m.items[1:], m.items)
+	m.items[0] = t
+	if len(m.items) > m.capacity {
+		m.items = m.items[:m.capacity]
+	}
+	m.changed()
+}
+
+// Remove drops t, reporting whether it was in the list.
+//goland:noinspection GoUnusedExportedFunction
+func (m *MRUList[T]) Remove(t T) bool {
+	m.mu.Lock()
+	if !m.remove(t) {
+		m.mu.Unlock()
+		return false
+	}
+	m.changed()
+	return true
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func (m *MRUList[T]) Clear() {
+	m.mu.Lock()
+	if len(m.items) == 0 {
+		m.mu.Unlock()
+		return
+	}
+	m.items = nil
+	m.changed()
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func (m *MRUList[T]) Get(ix int) (t T, b bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ix--
+	if ix > -1 && ix < len(m.items) {
+		t = m.items[ix]
+		b = true
+	}
+	return
+}
+
+// Items returns a copy of the items, most recent first.
+//goland:noinspection GoUnusedExportedFunction
+func (m *MRUList[T]) Items() []T {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.copyItems()
+}
+
+// AddListener registers f to be called with the items after every change.
+//goland:noinspection GoUnusedExportedFunction
+func (m *MRUList[T]) AddListener(f func([]T)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.listeners = append(m.listeners, f)
+}
+
+// Load replaces the items with those saved in path.
+//goland:noinspection GoUnusedExportedFunction
+func (m *MRUList[T]) Load(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var items []T
+	if err = json.Unmarshal(b, &items); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.items = m.items[:0]
+	for _, t := range items {
+		if len(m.items) < m.capacity && !m.contains(t) {
+			m.items = append(m.items, t)
+		}
+	}
+	items = m.copyItems()
+	listeners := m.listeners
+	m.mu.Unlock()
+	for _, f := range listeners {
+		f(items)
+	}
+	return nil
+}
+
+// Save writes the items to path (by way of a temporary file).
+//goland:noinspection GoUnusedExportedFunction
+func (m *MRUList[T]) Save(path string) error {
+	return saveJSON(path, m.Items())
+}
+
+// changed saves and notifies. Called with the lock held; unlocks.
+func (m *MRUList[T]) changed() {
+	items := m.copyItems()
+	listeners := m.listeners
+	path := m.path
+	m.mu.Unlock()
+	if path != "" {
+		if err := saveJSON(path, items); err != nil {
+			log.Println("MRUList save", err)
+		}
+	}
+	for _, f := range listeners {
+		f(items)
+	}
+}
+
+func (m *MRUList[T]) copyItems() []T {
+	items := make([]T, len(m.items))
+	copy(items, m.items)
+	return items
+}
+
+func (m *MRUList[T]) contains(t T) bool {
+	for _, v := range m.items {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MRUList[T]) remove(t T) bool {
+	for i, v := range m.items {
+		if v == t {
+			m.items = append(m.items[:i], m.items[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// saveJSON writes v to a temporary file beside path, then renames it.
+func saveJSON(path string, v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err = ioutil.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+	}
+	return err
+}
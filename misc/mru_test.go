@@ -0,0 +1,54 @@
+package misc
+
+import (
+	"fmt"
+	"testing"
+)
+
+/*
+
+  File:    mru_test.go
+  Author:  Bob Shofner
+
+*/
+
+func TestMRUList(t *testing.T) {
+	m := NewMRUList[string](3)
+	var heard []string
+	m.AddListener(func(items []string) { heard = items })
+	m.Touch("a")
+	m.Touch("b")
+	m.Touch("c")
+	m.Touch("a")
+	if got := fmt.Sprint(m.Items()); got != "[a c b]" {
+		t.Errorf("Items = %s; want [a c b]", got)
+	}
+	m.Touch("d")
+	if got := fmt.Sprint(heard); got != "[d a c]" {
+		t.Errorf("listener heard %s; want [d a c]", got)
+	}
+	if !m.Remove("a") || m.Remove("a") {
+		t.Error("Remove a: want true then false")
+	}
+	item, b := m.Get(2)
+	if !b || item != "c" {
+		t.Errorf("get 2; c got = %s", item)
+	}
+}
+
+func TestMRUListSave(t *testing.T) {
+	dir := t.TempDir()
+	m, err := OpenMRUList[string](dir, "recent", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Touch("/tmp/x")
+	m.Touch("/tmp/y")
+	again, err := OpenMRUList[string](dir, "recent", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(again.Items()); got != "[/tmp/y]" {
+		t.Errorf("loaded %s; want [/tmp/y]", got)
+	}
+}
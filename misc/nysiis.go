@@ -0,0 +1,123 @@
+package misc
+
+import "strings"
+
+/*
+
+  File:    nysiis.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: NYSIIS, New York State Identification and Intelligence
+    System phonetic code (Robert L. Taft, 1970).
+
+  The original algorithm: the name's prefix and suffix are rewritten,
+    then each letter after the first is transcoded in place (looking at
+    its neighbours), and repeats are written once. The code is cut to
+    6 characters (0 = no limit).
+*/
+
+type nysiis struct {
+	maxLen int
+}
+
+// NewNYSIIS makes a NYSIIS PhoneticEncoder, codes up to 6 characters.
+//goland:noinspection GoUnusedExportedFunction
+func NewNYSIIS() PhoneticEncoder {
+	return nysiis{maxLen: 6}
+}
+
+func (nysiis) Name() string {
+	return "nysiis"
+}
+
+var nysiisPrefix = [...][2]string{
+	{"MAC", "MCC"}, {"KN", "NN"}, {"K", "C"}, {"PH", "FF"}, {"PF", "FF"}, {"SCH", "SSS"},
+}
+var nysiisSuffix = [...][2]string{
+	{"EE", "Y"}, {"IE", "Y"}, {"DT", "D"}, {"RT", "D"}, {"RD", "D"}, {"NT", "D"}, {"ND", "D"},
+}
+
+func (n nysiis) Encode(word string) []string {
+	s := letters(word, latinFold)
+	if s == "" {
+		return nil
+	}
+	for _, p := range nysiisPrefix {
+		if strings.HasPrefix(s, p[0]) {
+			s = p[1] + s[len(p[0]):]
+			break
+		}
+	}
+	for _, p := range nysiisSuffix {
+		if strings.HasSuffix(s, p[0]) {
+			s = s[:len(s)-len(p[0])] + p[1]
+			break
+		}
+	}
+	chars := []byte(s)
+	key := []byte{chars[0]}
+	for i := 1; i < len(chars); i++ {
+		t := nysiisTranscode(chars, i)
+		copy(chars[i:], t)
+		if chars[i] != key[len(key)-1] {
+			key = append(key, chars[i])
+		}
+	}
+	if len(key) > 1 && key[len(key)-1] == 'S' {
+		key = key[:len(key)-1]
+	}
+	if len(key) > 2 && key[len(key)-2] == 'A' && key[len(key)-1] == 'Y' {
+		key = append(key[:len(key)-2], 'Y')
+	}
+	if len(key) > 1 && key[len(key)-1] == 'A' {
+		key = key[:len(key)-1]
+	}
+	if n.maxLen > 0 && len(key) > n.maxLen {
+		key = key[:n.maxLen]
+	}
+	return []string{string(key)}
+}
+
+// nysiisTranscode is what replaces chars[i] (and maybe those after it).
+func nysiisTranscode(chars []byte, i int) string {
+	s := string(chars)
+	curr, next := chars[i], charAt(s, i+1)
+	switch {
+	case curr == 'E' && next == 'V':
+		return "AF"
+	case isVowel(s, i):
+		return "A"
+	case curr == 'Q':
+		return "G"
+	case curr == 'Z':
+		return "S"
+	case curr == 'M':
+		return "N"
+	case curr == 'K':
+		if next == 'N' {
+			return "NN"
+		}
+		return "C"
+	case strings.HasPrefix(s[i:], "SCH"):
+		return "SSS"
+	case strings.HasPrefix(s[i:], "PH"):
+		return "FF"
+	case curr == 'H':
+		if !isVowel(s, i-1) || !isVowel(s, i+1) {
+			return string(chars[i-1])
+		}
+	case curr == 'W':
+		if isVowel(s, i-1) {
+			return string(chars[i-1])
+		}
+	}
+	return string(curr)
+}
@@ -0,0 +1,212 @@
+package misc
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+
+  File:    outline.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: Outline numbering. Levels are 1 relative.
+
+  Each level has its own style, prefix and suffix: I. A. 1. a. (1) ...
+  A Multilevel outline labels with every level joined by the Separator
+    (1.2.3); the prefix and suffix of the deepest level go around it all.
+  A level normally restarts when a higher level advances. RestartNever
+    keeps counting across them; Restart sets the next number explicitly.
+  Alphabetic numbers go on from z as aa, bb, cc (as word processors do).
+*/
+
+// NumberStyle is how one outline level writes its number.
+type NumberStyle int
+
+const (
+	NumberDecimal NumberStyle = iota // 1 2 3
+	NumberUpperAlpha                 // A B C
+	NumberLowerAlpha                 // a b c
+	NumberUpperRoman                 // I II III
+	NumberLowerRoman                 // i ii iii
+)
+
+// RestartRule says when a level goes back to its Start.
+type RestartRule int
+
+const (
+	RestartParent RestartRule = iota // when any higher level advances
+	RestartNever
+)
+
+// OutlineLevel describes one level of an Outline.
+type OutlineLevel struct {
+	Style   NumberStyle
+	Prefix  string
+	Suffix  string
+	Start   int // first number. 0 = 1
+	Restart RestartRule
+}
+
+type Outline struct {
+	Levels     []OutlineLevel
+	Multilevel bool   // label with every level (1.2.3)
+	Separator  string // between levels of a Multilevel label
+	counters   []int  // last number of each level
+	used       []bool // false = level not used (since its restart)
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func NewOutline(levels ...OutlineLevel) *Outline {
+	return &Outline{Levels: levels}
+}
+
+// NewLegalOutline numbers I. A. 1. a. (1) (a) (i)
+//goland:noinspection GoUnusedExportedFunction
+func NewLegalOutline() *Outline {
+	return NewOutline(
+		OutlineLevel{Style: NumberUpperRoman, Suffix: "."},
+		OutlineLevel{Style: NumberUpperAlpha, Suffix: "."},
+		OutlineLevel{Style: NumberDecimal, Suffix: "."},
+		OutlineLevel{Style: NumberLowerAlpha, Suffix: "."},
+		OutlineLevel{Style: NumberDecimal, Prefix: "(", Suffix: ")"},
+		OutlineLevel{Style: NumberLowerAlpha, Prefix: "(", Suffix: ")"},
+		OutlineLevel{Style: NumberLowerRoman, Prefix: "(", Suffix: ")"},
+	)
+}
+
+// NewDecimalOutline numbers 1, 1.1, 1.1.1 ... to depth levels, joined by sep.
+//goland:noinspection GoUnusedExportedFunction
+func NewDecimalOutline(depth int, sep string) *Outline {
+	return &Outline{Levels: make([]OutlineLevel, depth), Multilevel: true, Separator: sep}
+}
+
+// Next advances level and returns its label.
+//goland:noinspection GoUnusedExportedFunction
+func (o *Outline) Next(level int) (string, error) {
+	if level < 1 || level > len(o.Levels) {
+		return "", errors.New(fmt.Sprintf("outline level %d not in 1 thru %d", level, len(o.Levels)))
+	}
+	o.grow()
+	ix := level - 1
+	if o.used[ix] {
+		o.counters[ix]++
+	} else {
+		o.counters[ix] = o.start(ix)
+		o.used[ix] = true
+	}
+	for deeper := ix + 1; deeper < len(o.counters); deeper++ {
+		if o.Levels[deeper].Restart == RestartParent {
+			o.used[deeper] = false
+		}
+	}
+	return o.Label(level)
+}
+
+// Label is the current label of level, without advancing.
+//goland:noinspection GoUnusedExportedFunction
+func (o *Outline) Label(level int) (string, error) {
+	if level < 1 || level > len(o.Levels) {
+		return "", errors.New(fmt.Sprintf("outline level %d not in 1 thru %d", level, len(o.Levels)))
+	}
+	o.grow()
+	ix := level - 1
+	first := ix
+	if o.Multilevel {
+		first = 0
+	}
+	parts := make([]string, 0, level)
+	for i := first; i <= ix; i++ {
+		n := o.counters[i]
+		if !o.used[i] { // skipped level
+			n = o.start(i)
+		}
+		s, err := FormatNumber(n, o.Levels[i].Style)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, s)
+	}
+	l := o.Levels[ix]
+	return l.Prefix + strings.Join(parts, o.Separator) + l.Suffix, nil
+}
+
+// Restart makes n the next number of level.
+//goland:noinspection GoUnusedExportedFunction
+func (o *Outline) Restart(level int, n int) {
+	if level < 1 || level > len(o.Levels) {
+		return
+	}
+	o.grow()
+	o.counters[level-1] = n - 1
+	o.used[level-1] = true
+}
+
+// Reset starts the whole outline over.
+//goland:noinspection GoUnusedExportedFunction
+func (o *Outline) Reset() {
+	o.counters = nil
+	o.used = nil
+}
+
+// Counters returns the current number of each level (0 = not used yet).
+//goland:noinspection GoUnusedExportedFunction
+func (o *Outline) Counters() []int {
+	o.grow()
+	counters := make([]int, len(o.counters))
+	for i, n := range o.counters {
+		if o.used[i] {
+			counters[i] = n
+		}
+	}
+	return counters
+}
+
+func (o *Outline) start(ix int) int {
+	if o.Levels[ix].Start > 0 {
+		return o.Levels[ix].Start
+	}
+	return 1
+}
+
+func (o *Outline) grow() {
+	for len(o.counters) < len(o.Levels) {
+		o.counters = append(o.counters, 0)
+		o.used = append(o.used, false)
+	}
+}
+
+// FormatNumber writes n (1 or more) in style.
+//goland:noinspection GoUnusedExportedFunction
+func FormatNumber(n int, style NumberStyle) (string, error) {
+	if n < 1 && style != NumberDecimal {
+		return "", errors.New(fmt.Sprintf("%d has no letter or Roman form", n))
+	}
+	switch style {
+	case NumberUpperAlpha:
+		return alphaNumber(n, 'A'), nil
+	case NumberLowerAlpha:
+		return alphaNumber(n, 'a'), nil
+	case NumberUpperRoman:
+		return RomanStyled(n, RomanUpper, RomanStandard)
+	case NumberLowerRoman:
+		return RomanStyled(n, RomanLower, RomanStandard)
+	}
+	return strconv.Itoa(n), nil
+}
+
+// alphaNumber is a, b ... z, aa, bb ... zz, aaa
+func alphaNumber(n int, a rune) string {
+	n--
+	return strings.Repeat(string(a+rune(n%26)), n/26+1)
+}
@@ -0,0 +1,70 @@
+package misc
+
+import (
+	"strings"
+	"testing"
+)
+
+/*
+
+  File:    outline_test.go
+  Author:  Bob Shofner
+
+*/
+
+// labels advances each level in turn.
+func labels(t *testing.T, o *Outline, levels ...int) string {
+	t.Helper()
+	var got []string
+	for _, level := range levels {
+		s, err := o.Next(level)
+		if err != nil {
+			t.Fatalf("Next(%d): %v", level, err)
+		}
+		got = append(got, s)
+	}
+	return strings.Join(got, " ")
+}
+
+func TestLegalOutline(t *testing.T) {
+	o := NewLegalOutline()
+	want := "I. A. 1. a. (1) (2) b. B. II. A. 1."
+	if got := labels(t, o, 1, 2, 3, 4, 5, 5, 4, 2, 1, 2, 3); got != want {
+		t.Errorf("Expected %s: got %s", want, got)
+	}
+	if _, err := o.Next(8); err == nil {
+		t.Error("Expected an error for level 8")
+	}
+}
+
+func TestDecimalOutline(t *testing.T) {
+	o := NewDecimalOutline(3, ".")
+	want := "1 1.1 1.2 1.2.1 2 2.1 2.1.1"
+	if got := labels(t, o, 1, 2, 2, 3, 1, 2, 3); got != want {
+		t.Errorf("Expected %s: got %s", want, got)
+	}
+	o.Reset()
+	if got := labels(t, o, 3); got != "1.1.1" {
+		t.Errorf("skipped levels; got %s", got)
+	}
+}
+
+func TestOutlineRestart(t *testing.T) {
+	o := NewOutline(
+		OutlineLevel{Style: NumberUpperRoman, Suffix: "."},
+		OutlineLevel{Style: NumberLowerAlpha, Prefix: "(", Suffix: ")", Restart: RestartNever},
+	)
+	want := "I. (a) (b) II. (c)"
+	if got := labels(t, o, 1, 2, 2, 1, 2); got != want {
+		t.Errorf("Expected %s: got %s", want, got)
+	}
+	o.Restart(2, 26)
+	if got := labels(t, o, 2, 2, 2); got != "(z) (aa) (bb)" {
+		t.Errorf("Expected (z) (aa) (bb): got %s", got)
+	}
+	o.Multilevel = true
+	o.Separator = "-"
+	if s, _ := o.Label(2); s != "(II-bb)" {
+		t.Errorf("Expected (II-bb): got %s", s)
+	}
+}
@@ -0,0 +1,149 @@
+package misc
+
+import (
+	"strings"
+	"unicode"
+)
+
+/*
+
+  File:    phonetic.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: Phonetic (sounds like) name encoders.
+
+  Every algorithm implements PhoneticEncoder, so a caller can pick one
+    by name. Encode returns every code of a word: Daitch-Mokotoff may
+    branch into several, Double Metaphone gives a primary and alternate.
+  Two words sound alike (PhoneticMatch) if they share any code.
+
+  soundex           American Soundex (SoundexCode)
+  daitch-mokotoff   Daitch-Mokotoff Soundex, for Slavic and Yiddish names
+  nysiis            New York State Identification and Intelligence System
+  metaphone         Lawrence Philips' Metaphone
+  double-metaphone  Lawrence Philips' Double Metaphone
+  cologne           Kölner Phonetik, for German names
+*/
+
+// The PhoneticEncoder interface describes a phonetic algorithm.
+type PhoneticEncoder interface {
+	// Name of the algorithm
+	Name() string
+	// Encode returns the codes for a word. none for a word without letters.
+	Encode(word string) []string
+}
+
+// PhoneticEncoders lists every algorithm.
+//goland:noinspection GoUnusedExportedFunction
+func PhoneticEncoders() []PhoneticEncoder {
+	return []PhoneticEncoder{
+		NewSoundex(),
+		NewDaitchMokotoff(),
+		NewNYSIIS(),
+		NewMetaphone(),
+		NewDoubleMetaphone(),
+		NewCologne(),
+	}
+}
+
+// PhoneticEncoderByName finds an algorithm by its Name. nil if unknown.
+//goland:noinspection GoUnusedExportedFunction
+func PhoneticEncoderByName(name string) PhoneticEncoder {
+	for _, e := range PhoneticEncoders() {
+		if strings.EqualFold(e.Name(), name) {
+			return e
+		}
+	}
+	return nil
+}
+
+// PhoneticMatch reports whether a and b share any code.
+//goland:noinspection GoUnusedExportedFunction
+func PhoneticMatch(e PhoneticEncoder, a, b string) bool {
+	codes := e.Encode(a)
+	for _, y := range e.Encode(b) {
+		for _, x := range codes {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type soundexEncoder struct{}
+
+// NewSoundex adapts SoundexCode to PhoneticEncoder.
+//goland:noinspection GoUnusedExportedFunction
+func NewSoundex() PhoneticEncoder {
+	return soundexEncoder{}
+}
+
+func (soundexEncoder) Name() string {
+	return "soundex"
+}
+func (soundexEncoder) Encode(word string) []string {
+	if soundexLetters(word) == "" {
+		return nil
+	}
+	return SoundexCodes(word, true)
+}
+
+// letters upper cases word, keeping only the letters A thru Z.
+// fold (if any) replaces other letters first (Ä as A).
+func letters(word string, fold map[rune]string) string {
+	var b strings.Builder
+	for _, r := range upperWord(word) {
+		if s, ok := fold[r]; ok {
+			b.WriteString(s)
+		} else if r >= 'A' && r <= 'Z' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// latinFold replaces the accented Latin letters common in names.
+var latinFold = map[rune]string{
+	'À': "A", 'Á': "A", 'Â': "A", 'Ã': "A", 'Ä': "A", 'Å': "A", 'Ą': "A",
+	'Ç': "C", 'Ć': "C", 'Č': "C", 'Ď': "D", 'Ð': "D", 'Đ': "D",
+	'È': "E", 'É': "E", 'Ê': "E", 'Ë': "E", 'Ę': "E", 'Ě': "E",
+	'Ì': "I", 'Í': "I", 'Î': "I", 'Ï': "I", 'Ł': "L",
+	'Ñ': "N", 'Ń': "N", 'Ň': "N",
+	'Ò': "O", 'Ó': "O", 'Ô': "O", 'Õ': "O", 'Ö': "O", 'Ø': "O",
+	'Ř': "R", 'Ś': "S", 'Š': "S", 'ẞ': "SS", 'Ť': "T", 'Ţ': "T",
+	'Ù': "U", 'Ú': "U", 'Û': "U", 'Ü': "U", 'Ů': "U",
+	'Ý': "Y", 'Ÿ': "Y", 'Ź': "Z", 'Ż': "Z", 'Ž': "Z",
+	'Æ': "AE", 'Œ': "OE", 'Þ': "TH",
+}
+
+// isVowel reports whether s[i] is A, E, I, O or U (false out of range).
+func isVowel(s string, i int) bool {
+	return i >= 0 && i < len(s) && strings.IndexByte("AEIOU", s[i]) >= 0
+}
+
+// charAt is s[i], or 0 out of range.
+func charAt(s string, i int) byte {
+	if i < 0 || i >= len(s) {
+		return 0
+	}
+	return s[i]
+}
+
+// upperWord is ToUpper, also of ß (ToUpper leaves it).
+func upperWord(word string) string {
+	return strings.Map(func(r rune) rune {
+		if r == 'ß' {
+			return 'ẞ'
+		}
+		return unicode.ToUpper(r)
+	}, word)
+}
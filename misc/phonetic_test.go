@@ -0,0 +1,98 @@
+package misc
+
+import (
+	"strings"
+	"testing"
+)
+
+/*
+
+  File:    phonetic_test.go
+  Author:  Bob Shofner
+
+*/
+/*
+  Description: reference codes published with each algorithm
+    (or by its widely used implementations). Several codes are joined by "|".
+*/
+
+func TestPhoneticEncoders(t *testing.T) {
+	var tests = []struct {
+		encoder string
+		word    string
+		want    string
+	}{
+		{"soundex", "Robert", "R163"},
+		{"soundex", "", ""},
+
+		{"daitch-mokotoff", "Auerbach", "097500|097400"},
+		{"daitch-mokotoff", "Ohrbach", "097500|097400"},
+		{"daitch-mokotoff", "Lipshitz", "874400"},
+		{"daitch-mokotoff", "Lippszyc", "874500|874400"},
+		{"daitch-mokotoff", "Moskowitz", "645740"},
+		{"daitch-mokotoff", "Moskovitz", "645740"},
+		{"daitch-mokotoff", "Jackson", "154600|145460|454600|445460"},
+
+		{"nysiis", "Brian", "BRAN"},
+		{"nysiis", "Brown", "BRAN"},
+		{"nysiis", "Capp", "CAP"},
+		{"nysiis", "Kipp", "CAP"},
+		{"nysiis", "Dent", "DAD"},
+		{"nysiis", "Dionne", "DAN"},
+		{"nysiis", "Smith", "SNAT"},
+		{"nysiis", "Schmit", "SNAT"},
+		{"nysiis", "Schmidt", "SNAD"},
+		{"nysiis", "Truman", "TRANAN"},
+		{"nysiis", "O'Daniel", "ODANAL"},
+		{"nysiis", "Corley", "CARLY"},
+		{"nysiis", "Kelly", "CALY"},
+
+		{"metaphone", "howl", "HL"},
+		{"metaphone", "The", "0"},
+		{"metaphone", "quick", "KK"},
+		{"metaphone", "brown", "BRN"},
+		{"metaphone", "fox", "FKS"},
+		{"metaphone", "jumped", "JMPT"},
+		{"metaphone", "over", "OFR"},
+		{"metaphone", "lazy", "LS"},
+		{"metaphone", "dogs", "TKS"},
+		{"metaphone", "Knight", "NT"},
+
+		{"double-metaphone", "Smith", "SM0|XMT"},
+		{"double-metaphone", "Schmidt", "XMT|SMT"},
+		{"double-metaphone", "Xavier", "SF|SFR"},
+		{"double-metaphone", "Jose", "HS"},
+		{"double-metaphone", "Arnow", "ARN|ARNF"},
+		{"double-metaphone", "Arnoff", "ARNF"},
+		{"double-metaphone", "Caesar", "SSR"},
+		{"double-metaphone", "Dumb", "TM"},
+		{"double-metaphone", "Campbell", "KMPL"},
+		{"double-metaphone", "Filipowicz", "FLPT|FLPF"},
+
+		{"cologne", "Müller-Lüdenscheidt", "65752682"},
+		{"cologne", "Wikipedia", "3412"},
+		{"cologne", "Breschnew", "17863"},
+		{"cologne", "Meier", "67"},
+		{"cologne", "Mayr", "67"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.encoder+","+tt.word, func(t *testing.T) {
+			e := PhoneticEncoderByName(tt.encoder)
+			if e == nil {
+				t.Fatalf("no encoder %s", tt.encoder)
+			}
+			if got := strings.Join(e.Encode(tt.word), "|"); got != tt.want {
+				t.Errorf("Expected %s: got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPhoneticMatch(t *testing.T) {
+	if !PhoneticMatch(NewDoubleMetaphone(), "Smith", "Schmidt") {
+		t.Error("Smith should sound like Schmidt")
+	}
+	if PhoneticMatch(NewCologne(), "Meier", "Schmidt") {
+		t.Error("Meier should not sound like Schmidt")
+	}
+}
@@ -0,0 +1,122 @@
+package misc
+
+import "container/heap"
+
+/*
+
+  File:    priority.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: generic priority queue (a binary heap).
+
+  less(a, b) is true when a comes out before b. Push returns the
+    PriorityItem, which is used to Update (or Remove) it while queued.
+*/
+
+// PriorityItem is an item in a PriorityQueue.
+type PriorityItem[T any] struct {
+	Value T
+	index int // in the heap, -1 when not queued
+}
+
+type PriorityQueue[T any] struct {
+	h priorityHeap[T]
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func NewPriorityQueue[T any](less func(a, b T) bool) *PriorityQueue[T] {
+	return &PriorityQueue[T]{h: priorityHeap[T]{less: less}}
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func (q *PriorityQueue[T]) Count() int {
+	return len(q.h.items)
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func (q *PriorityQueue[T]) Push(value T) *PriorityItem[T] {
+	item := &PriorityItem[T]{Value: value}
+	heap.Push(&q.h, item)
+	return item
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func (q *PriorityQueue[T]) Peek() (T, bool) {
+	var value T
+	if len(q.h.items) > 0 {
+		return q.h.items[0].Value, true
+	}
+	return value, false
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func (q *PriorityQueue[T]) Pop() (T, bool) {
+	var value T
+	if len(q.h.items) > 0 {
+		return heap.Pop(&q.h).(*PriorityItem[T]).Value, true
+	}
+	return value, false
+}
+
+// Update changes the value (and so the priority) of a queued item.
+// It returns false if the item is no longer queued.
+//goland:noinspection GoUnusedExportedFunction
+func (q *PriorityQueue[T]) Update(item *PriorityItem[T], value T) bool {
+	if !q.queued(item) {
+		return false
+	}
+	item.Value = value
+	heap.Fix(&q.h, item.index)
+	return true
+}
+
+// Remove takes a queued item out of the queue.
+//goland:noinspection GoUnusedExportedFunction
+func (q *PriorityQueue[T]) Remove(item *PriorityItem[T]) bool {
+	if !q.queued(item) {
+		return false
+	}
+	heap.Remove(&q.h, item.index)
+	return true
+}
+
+func (q *PriorityQueue[T]) queued(item *PriorityItem[T]) bool {
+	return item != nil && item.index >= 0 && item.index < len(q.h.items) && q.h.items[item.index] == item
+}
+
+// priorityHeap implements heap.Interface.
+type priorityHeap[T any] struct {
+	items []*PriorityItem[T]
+	less  func(a, b T) bool
+}
+
+func (h *priorityHeap[T]) Len() int { return len(h.items) }
+func (h *priorityHeap[T]) Less(i, j int) bool {
+	return h.less(h.items[i].Value, h.items[j].Value)
+}
+func (h *priorityHeap[T]) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.items[i].index = i
+	h.items[j].index = j
+}
+func (h *priorityHeap[T]) Push(x any) {
+	item := x.(*PriorityItem[T])
+	item.index = len(h.items)
+	h.items = append(h.items, item)
+}
+func (h *priorityHeap[T]) Pop() any {
+	n := len(h.items) - 1
+	item := h.items[n]
+	h.items[n] = nil
+	h.items = h.items[:n]
+	item.index = -1
+	return item
+}
@@ -0,0 +1,46 @@
+package misc
+
+import "testing"
+
+/*
+
+  File:    priority_test.go
+  Author:  Bob Shofner
+
+*/
+
+type job struct {
+	name     string
+	priority int
+}
+
+func TestPriorityQueue(t *testing.T) {
+	q := NewPriorityQueue[job](func(a, b job) bool { return a.priority > b.priority })
+	q.Push(job{"low", 1})
+	mid := q.Push(job{"mid", 5})
+	q.Push(job{"high", 9})
+	gone := q.Push(job{"gone", 3})
+	item, b := q.Peek()
+	if !b || item.name != "high" {
+		t.Errorf("peek; high got = %s", item.name)
+	}
+	if !q.Update(mid, job{"mid", 10}) {
+		t.Error("Update of queued item failed")
+	}
+	if !q.Remove(gone) || q.Remove(gone) {
+		t.Error("Remove: want true then false")
+	}
+	for _, want := range []string{"mid", "high", "low"} {
+		item, b = q.Pop()
+		if !b || item.name != want {
+			t.Errorf("pop; %s got = %s", want, item.name)
+		}
+	}
+	if q.Update(mid, job{"mid", 0}) {
+		t.Error("Update of popped item")
+	}
+	item, b = q.Pop()
+	if b || q.Count() != 0 {
+		t.Errorf("empty pop; got = %s", item.name)
+	}
+}
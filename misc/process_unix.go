@@ -0,0 +1,38 @@
+//go:build !windows
+// +build !windows
+
+package misc
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+/*
+
+  File:    process_unix.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: Is a process running, is a file mine (unix).
+*/
+
+// processAlive reports whether the process pid exists. Signal 0 checks without sending.
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// ownedByMe reports whether the file of info belongs to this user.
+func ownedByMe(info os.FileInfo) bool {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	return ok && int(st.Uid) == os.Getuid()
+}
@@ -0,0 +1,50 @@
+//go:build windows
+// +build windows
+
+package misc
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+/*
+
+  File:    process_windows.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: Is a process running, is a file mine (windows).
+
+  The temp directory is in the user's profile, so every file of it is taken as mine.
+*/
+
+// stillActive is the exit code of a process that has not exited.
+const stillActive = 259
+
+// processAlive reports whether the process pid exists and has not exited.
+func processAlive(pid int) bool {
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return err == windows.ERROR_ACCESS_DENIED // someone else's
+	}
+	defer func() { _ = windows.CloseHandle(h) }()
+	var code uint32
+	if err = windows.GetExitCodeProcess(h, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
+
+// ownedByMe reports whether the file of info belongs to this user.
+func ownedByMe(os.FileInfo) bool {
+	return true
+}
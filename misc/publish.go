@@ -1,6 +1,13 @@
 package misc
 
-import "sync"
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
 
 /*
 
@@ -16,85 +23,574 @@ import "sync"
 */
 /*
   Description: Publish / Subscribe.
+
+  Messages are published on a topic. Topics are words separated by '.'
+    (e.g. "file.saved"). A subscription pattern may use '*' to match
+    exactly one word and '#' to match zero or more words.
+    Register subscribes to every topic ("#").
+
+  Every subscriber has its own buffer, so one slow subscriber does not
+    hold up the others unless it asked for the Block policy.
+  Unregister (and the end of a Subscribe) stops a subscriber at once,
+    without waiting for the delivery of other messages, so a stalled
+    Block subscriber can always be removed.
+  Close drains for at most PublisherCloseTimeout; a subscriber that has
+    stopped reading then loses what is pending. CloseContext drains until
+    its ctx ends, so with context.Background() it can block forever.
 */
 
-type publisher struct {
-	lock        sync.Mutex
-	input       chan interface{}
-	subscribe   chan chan<- interface{}
-	unsubscribe chan chan<- interface{}
-	outputs     map[chan<- interface{}]bool
+// PublisherCloseTimeout is how long Close waits for the subscribers to take pending messages.
+var PublisherCloseTimeout = 5 * time.Second
+
+// AllTopics is the pattern matching every topic.
+const AllTopics = "#"
+
+// ErrPublisherClosed is returned by any call made after Close.
+var ErrPublisherClosed = errors.New("publisher is closed")
+
+// ErrPublisherFull is returned by Submit when the input buffer has no room.
+var ErrPublisherFull = errors.New("publisher input is full")
+
+// OverflowPolicy decides what happens when a subscriber's buffer is full.
+type OverflowPolicy int
+
+const (
+	// DropOldest discards the oldest buffered message to make room.
+	DropOldest OverflowPolicy = iota
+	// DropNewest discards the message being published.
+	DropNewest
+	// Block waits for the subscriber to make room. This holds up every subscriber.
+	Block
+)
+
+// CloseMode decides what happens to messages still pending at Close.
+type CloseMode int
+
+const (
+	// CloseDrain delivers every pending message before closing the subscriber channels.
+	CloseDrain CloseMode = iota
+	// CloseDiscard throws pending messages away.
+	CloseDiscard
+)
+
+// SubscribeOptions configures a single subscription.
+type SubscribeOptions struct {
+	Buffer int            // messages held for the subscriber (minimum 1)
+	Policy OverflowPolicy // what to do when Buffer is full
+	Replay bool           // first deliver the retained (replay) messages that match
+}
+
+// PublisherMetrics counts the traffic through a Publisher.
+type PublisherMetrics struct {
+	Submitted   uint64 // accepted by Submit
+	Rejected    uint64 // refused by Submit (input buffer full or closed)
+	Delivered   uint64 // handed to a subscriber channel
+	Dropped     uint64 // discarded by an overflow policy or CloseDiscard
+	Subscribers int
 }
 
 // The Publisher interface describes the main entry points to Publisher(s).
-type Publisher interface {
-	// Register a new channel to receive broadcasts
-	Register(chan<- interface{})
+//
+// Close ends the publisher: Submit, Register and Unregister then return
+// ErrPublisherClosed and every registered channel is closed, so a subscriber
+// ranging over its channel finishes. A channel must not be registered with
+// more than one publisher.
+type Publisher[T any] interface {
+	// Register a new channel to receive all broadcasts
+	Register(chan<- T) error
+	// RegisterTopic registers a new channel to receive broadcasts matching pattern.
+	RegisterTopic(pattern string, ch chan<- T, opts SubscribeOptions) error
 	// Unregister a channel so that it no longer receives broadcasts.
-	Unregister(chan<- interface{})
-	// Close this publisher
+	Unregister(chan<- T) error
+	// Subscribe creates a channel receiving broadcasts matching pattern.
+	// The channel is closed when ctx ends, when unsubscribe is called or when the publisher closes.
+	Subscribe(ctx context.Context, pattern string, opts SubscribeOptions) (ch <-chan T, unsubscribe func())
+	// Close this publisher, delivering any pending messages first (for at most
+	// PublisherCloseTimeout, then context.DeadlineExceeded).
 	Close() error
-	// Submit a new object to all subscribers return false if input chan is full
-	Submit(interface{}) bool
+	// CloseContext closes this publisher. Pending messages are delivered or
+	// discarded per mode; when ctx ends first the rest are discarded and ctx.Err() returned.
+	// A subscriber that stops reading blocks the drain until ctx ends.
+	CloseContext(ctx context.Context, mode CloseMode) error
+	// Submit a new object to all subscribers. ErrPublisherFull if input chan is full
+	Submit(T) error
+	// SubmitTopic submits a new object on a topic. ErrPublisherFull if input chan is full
+	SubmitTopic(topic string, m T) error
+	// Metrics reports the message counts so far.
+	Metrics() PublisherMetrics
+}
+
+// envelope is a message along with its topic.
+type envelope[T any] struct {
+	topic string
+	m     T
+}
+
+type publisher[T any] struct {
+	state      sync.RWMutex // guards closed and sending on input
+	closed     bool
+	input      chan envelope[T]
+	subscribe  chan *subscriber[T]
+	outputs    map[chan<- T]*subscriber[T] // owned by run
+	mu         sync.Mutex                  // guards subs
+	subs       map[chan<- T]*subscriber[T] // the registered subscribers, for Unregister
+	replay     []envelope[T]
+	replaySize int
+	metrics    PublisherMetrics
+	pumps      sync.WaitGroup
+	abort      chan struct{} // closed to stop delivering
+	abortOnce  sync.Once
+	done       chan struct{} // closed when run has finished
 }
 
-// run - forever handling requests.
-func (p *publisher) run() {
+// NewPublisher creates a publisher with the given channel buffer length.
+//goland:noinspection GoUnusedExportedFunction
+func NewPublisher[T any](buflen int) Publisher[T] {
+	return NewReplayPublisher[T](buflen, 0)
+}
+
+// NewReplayPublisher creates a publisher that also retains the last replay
+// messages for subscribers that ask for them.
+//goland:noinspection GoUnusedExportedFunction
+func NewReplayPublisher[T any](buflen, replay int) Publisher[T] {
+	p := &publisher[T]{
+		input:      make(chan envelope[T], buflen), // bi-directional
+		subscribe:  make(chan *subscriber[T]),
+		outputs:    make(map[chan<- T]*subscriber[T]),
+		subs:       make(map[chan<- T]*subscriber[T]),
+		replaySize: replay,
+		abort:      make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+// run - handling requests until the input is closed.
+func (p *publisher[T]) run() {
 	for {
 		select {
-		case m := <-p.input: // publish (from p.Submit(m interface{}))
-			for ch := range p.outputs {
-				ch <- m
-			}
-		case ch, ok := <-p.subscribe: // new subscriber
-			if ok {
-				p.outputs[ch] = true
-			} else {
+		case e, ok := <-p.input: // publish (from p.Submit(m))
+			if !ok {
+				p.shutdown()
 				return
 			}
-		case ch := <-p.unsubscribe: // quit subscribing
-			delete(p.outputs, ch)
+			if stopped(p.abort) {
+				atomic.AddUint64(&p.metrics.Dropped, 1)
+				continue
+			}
+			p.retain(e)
+			for ch, s := range p.outputs {
+				if stopped(s.done) { // unregistered
+					delete(p.outputs, ch)
+					continue
+				}
+				if TopicMatch(s.pattern, e.topic) {
+					s.offer(e.m)
+				}
+			}
+		case s := <-p.subscribe: // new subscriber
+			if old, ok := p.outputs[s.out]; ok {
+				old.stop()
+			}
+			p.outputs[s.out] = s
+			p.pumps.Add(1)
+			go func() {
+				defer p.pumps.Done()
+				s.pump()
+			}()
+			if s.replay {
+				for _, e := range p.replay {
+					if TopicMatch(s.pattern, e.topic) {
+						s.offer(e.m)
+					}
+				}
+			}
 		}
 	}
 }
 
-// NewPublisher creates a publisher with the given channel buffer length.
-//goland:noinspection GoUnusedExportedFunction
-func NewPublisher(buflen int) Publisher {
-	p := &publisher{
-		input:       make(chan interface{}, buflen),    // bi-directional
-		subscribe:   make(chan chan<- interface{}),     // send only
-		unsubscribe: make(chan chan<- interface{}),     // send only
-		outputs:     make(map[chan<- interface{}]bool), // send only
+// shutdown lets the pumps finish, then closes every subscriber channel.
+func (p *publisher[T]) shutdown() {
+	for _, s := range p.outputs {
+		close(s.closing)
 	}
-	go p.run()
-	return p
+	p.pumps.Wait()
+	for ch, s := range p.outputs {
+		atomic.AddUint64(&p.metrics.Dropped, uint64(s.discard()))
+		if s.owned || !stopped(s.done) { // an unregistered channel is the caller's again
+			s.closeOut()
+		}
+		delete(p.outputs, ch)
+	}
+	p.mu.Lock()
+	p.subs = make(map[chan<- T]*subscriber[T])
+	p.mu.Unlock()
+	close(p.done)
+}
+
+// retain keeps the last replaySize messages.
+func (p *publisher[T]) retain(e envelope[T]) {
+	if p.replaySize < 1 {
+		return
+	}
+	if len(p.replay) == p.replaySize {
+		p.replay = append(p.replay[:0], p.replay[1:]...)
+	}
+	p.replay = append(p.replay, e)
+}
+
+func (p *publisher[T]) isClosed() bool {
+	p.state.RLock()
+	defer p.state.RUnlock()
+	return p.closed
+}
+
+func (p *publisher[T]) stopDelivery() {
+	p.abortOnce.Do(func() {
+		close(p.abort)
+	})
+}
+
+func (p *publisher[T]) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), PublisherCloseTimeout)
+	defer cancel()
+	return p.CloseContext(ctx, CloseDrain)
+}
+func (p *publisher[T]) CloseContext(ctx context.Context, mode CloseMode) error {
+	p.state.Lock()
+	if p.closed {
+		p.state.Unlock()
+		return ErrPublisherClosed
+	}
+	p.closed = true
+	close(p.input)
+	p.state.Unlock()
+	if mode == CloseDiscard {
+		p.stopDelivery()
+	}
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		p.stopDelivery()
+		<-p.done
+		return ctx.Err()
+	}
+}
+func (p *publisher[T]) Register(ch chan<- T) error {
+	return p.RegisterTopic(AllTopics, ch, SubscribeOptions{Buffer: 1, Policy: Block})
 }
-func (p *publisher) Close() error {
-	defer p.lock.Unlock()
-	p.lock.Lock()
-	close(p.subscribe)
-	close(p.unsubscribe)
+func (p *publisher[T]) RegisterTopic(pattern string, ch chan<- T, opts SubscribeOptions) error {
+	if p.isClosed() {
+		return ErrPublisherClosed
+	}
+	return p.add(newSubscriber[T](pattern, ch, opts, &p.metrics, p.abort))
+}
+
+// add hands s to run and records it for Unregister.
+func (p *publisher[T]) add(s *subscriber[T]) error {
+	select {
+	case p.subscribe <- s:
+	case <-p.done:
+		return ErrPublisherClosed
+	}
+	p.mu.Lock()
+	old := p.subs[s.out]
+	p.subs[s.out] = s
+	p.mu.Unlock()
+	if old != nil {
+		old.stop()
+	}
 	return nil
 }
-func (p *publisher) Register(ch chan<- interface{}) {
-	defer p.lock.Unlock()
-	p.lock.Lock()
-	p.subscribe <- ch
+func (p *publisher[T]) Subscribe(ctx context.Context, pattern string,
+	opts SubscribeOptions) (<-chan T, func()) {
+	ch := make(chan T)
+	s := newSubscriber[T](pattern, ch, opts, &p.metrics, p.abort)
+	s.owned = true
+	if p.isClosed() {
+		close(ch)
+		return ch, func() {}
+	}
+	if p.add(s) != nil {
+		close(ch)
+		return ch, func() {}
+	}
+	quit := make(chan struct{})
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			close(quit)
+			_ = p.Unregister(ch)
+		})
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+			unsubscribe()
+		case <-quit:
+		case <-p.done:
+		}
+	}()
+	return ch, unsubscribe
 }
-func (p *publisher) Unregister(ch chan<- interface{}) {
-	defer p.lock.Unlock()
-	p.lock.Lock()
-	p.unsubscribe <- ch
+func (p *publisher[T]) Unregister(ch chan<- T) error {
+	if p.isClosed() {
+		return ErrPublisherClosed
+	}
+	p.mu.Lock()
+	s, ok := p.subs[ch]
+	delete(p.subs, ch)
+	p.mu.Unlock()
+	if ok {
+		s.stop() // run drops it at its next message
+		if s.owned {
+			go func() {
+				<-s.exited
+				s.closeOut()
+			}()
+		}
+	}
+	return nil
 }
 
 // Submit attempts to submit an item to be published, returning
-// true if successful, else false.
-func (p *publisher) Submit(m interface{}) bool {
-	select { // block until a case can run and buffer has room
-	case p.input <- m:
-		return true
+// nil if successful, else why not.
+func (p *publisher[T]) Submit(m T) error {
+	return p.SubmitTopic("", m)
+}
+
+// SubmitTopic attempts to submit an item to be published on topic, returning
+// nil if successful, else why not.
+func (p *publisher[T]) SubmitTopic(topic string, m T) error {
+	p.state.RLock()
+	defer p.state.RUnlock()
+	if p.closed {
+		atomic.AddUint64(&p.metrics.Rejected, 1)
+		return ErrPublisherClosed
+	}
+	select { // never block; the buffer has room or not
+	case p.input <- envelope[T]{topic: topic, m: m}:
+		atomic.AddUint64(&p.metrics.Submitted, 1)
+		return nil
 	default: // input channel is not ready. ignore
+		atomic.AddUint64(&p.metrics.Rejected, 1)
+		return ErrPublisherFull
+	}
+}
+
+func (p *publisher[T]) Metrics() PublisherMetrics {
+	return PublisherMetrics{
+		Submitted:   atomic.LoadUint64(&p.metrics.Submitted),
+		Rejected:    atomic.LoadUint64(&p.metrics.Rejected),
+		Delivered:   atomic.LoadUint64(&p.metrics.Delivered),
+		Dropped:     atomic.LoadUint64(&p.metrics.Dropped),
+		Subscribers: p.subscribers(),
+	}
+}
+
+func (p *publisher[T]) subscribers() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.subs)
+}
+
+// subscriber buffers messages for one channel. The publisher's run loop
+// offers messages; pump hands them to the channel.
+type subscriber[T any] struct {
+	pattern string
+	out     chan<- T
+	policy  OverflowPolicy
+	size    int
+	replay  bool
+	metrics *PublisherMetrics
+	mu      sync.Mutex
+	queue   []T
+	ready   chan struct{} // something was queued
+	space   chan struct{} // something was taken
+	done    chan struct{} // subscription ended
+	closing chan struct{} // publisher closing; deliver what is queued
+	abort   chan struct{} // publisher closing; stop delivering
+	exited  chan struct{} // pump has returned
+	owned   bool          // out was made by Subscribe; close it when the subscription ends
+	stopper sync.Once
+	closer  sync.Once
+}
+
+func newSubscriber[T any](pattern string, ch chan<- T, opts SubscribeOptions,
+	metrics *PublisherMetrics, abort chan struct{}) *subscriber[T] {
+	if opts.Buffer < 1 {
+		opts.Buffer = 1
+	}
+	return &subscriber[T]{
+		pattern: pattern,
+		out:     ch,
+		policy:  opts.Policy,
+		size:    opts.Buffer,
+		replay:  opts.Replay,
+		metrics: metrics,
+		queue:   make([]T, 0, opts.Buffer),
+		ready:   make(chan struct{}, 1),
+		space:   make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		closing: make(chan struct{}),
+		abort:   abort,
+		exited:  make(chan struct{}),
+	}
+}
+
+// offer queues m according to the overflow policy.
+func (s *subscriber[T]) offer(m T) {
+	for {
+		s.mu.Lock()
+		if len(s.queue) < s.size {
+			s.queue = append(s.queue, m)
+			s.mu.Unlock()
+			signal(s.ready)
+			return
+		}
+		switch s.policy {
+		case DropNewest:
+			s.mu.Unlock()
+			atomic.AddUint64(&s.metrics.Dropped, 1)
+			return
+		case DropOldest:
+			s.queue = append(s.queue[:0], s.queue[1:]...)
+			s.queue = append(s.queue, m)
+			s.mu.Unlock()
+			atomic.AddUint64(&s.metrics.Dropped, 1)
+			signal(s.ready)
+			return
+		}
+		s.mu.Unlock()
+		select { // Block
+		case <-s.space:
+		case <-s.done:
+			return
+		case <-s.abort:
+			atomic.AddUint64(&s.metrics.Dropped, 1)
+			return
+		}
+	}
+}
+
+// take removes the next queued message.
+func (s *subscriber[T]) take() (m T, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.queue) > 0 {
+		m, ok = s.queue[0], true
+		s.queue = append(s.queue[:0], s.queue[1:]...)
+	}
+	return
+}
+
+// discard empties the queue, returning how many were thrown away.
+func (s *subscriber[T]) discard() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := len(s.queue)
+	s.queue = s.queue[:0]
+	return n
+}
+
+// pump delivers queued messages until the subscription ends,
+// or until the queue is empty once the publisher is closing.
+func (s *subscriber[T]) pump() {
+	defer close(s.exited)
+	for {
+		for m, ok := s.take(); ok; m, ok = s.take() {
+			signal(s.space)
+			select {
+			case s.out <- m:
+				atomic.AddUint64(&s.metrics.Delivered, 1)
+			case <-s.done:
+				return
+			case <-s.abort:
+				atomic.AddUint64(&s.metrics.Dropped, 1)
+				return
+			}
+		}
+		select {
+		case <-s.ready:
+		case <-s.done:
+			return
+		case <-s.abort:
+			return
+		case <-s.closing:
+			s.mu.Lock()
+			empty := len(s.queue) == 0
+			s.mu.Unlock()
+			if empty {
+				return
+			}
+		}
+	}
+}
+
+func (s *subscriber[T]) stop() {
+	s.stopper.Do(func() {
+		close(s.done)
+	})
+}
+
+// closeOut closes the channel once; after pump has returned.
+func (s *subscriber[T]) closeOut() {
+	s.closer.Do(func() {
+		close(s.out)
+	})
+}
+
+// stopped reports whether ch has been closed.
+func stopped(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
 		return false
 	}
 }
+
+// signal wakes a waiter without blocking.
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// TopicMatch reports whether topic matches pattern. Words are separated by '.',
+// '*' matches exactly one word and '#' matches zero or more words.
+//goland:noinspection GoUnusedExportedFunction
+func TopicMatch(pattern, topic string) bool {
+	if pattern == AllTopics || pattern == topic {
+		return true
+	}
+	return matchWords(strings.Split(pattern, "."), strings.Split(topic, "."))
+}
+
+func matchWords(pattern, topic []string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case "#":
+			for i := 0; i <= len(topic); i++ {
+				if matchWords(pattern[1:], topic[i:]) {
+					return true
+				}
+			}
+			return false
+		case "*":
+			if len(topic) == 0 {
+				return false
+			}
+		default:
+			if len(topic) == 0 || topic[0] != pattern[0] {
+				return false
+			}
+		}
+		pattern = pattern[1:]
+		topic = topic[1:]
+	}
+	return len(topic) == 0
+}
@@ -0,0 +1,248 @@
+package misc
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+/*
+
+  File:    publish_test.go
+  Author:  Bob Shofner
+
+*/
+
+func TestTopicMatch(t *testing.T) {
+	var tests = []struct {
+		pattern string
+		topic   string
+		match   bool
+	}{
+		{"#", "", true},
+		{"#", "file.saved", true},
+		{"file.saved", "file.saved", true},
+		{"file.*", "file.saved", true},
+		{"file.*", "file", false},
+		{"file.*", "file.saved.twice", false},
+		{"file.#", "file", true},
+		{"file.#", "file.saved.twice", true},
+		{"*.saved", "file.saved", true},
+		{"*.saved", "theme.changed", false},
+		{"#.changed", "theme.color.changed", true},
+		{"theme", "file", false},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s,%s", tt.pattern, tt.topic), func(t *testing.T) {
+			if m := TopicMatch(tt.pattern, tt.topic); m != tt.match {
+				t.Errorf("Expected %t: got %t", tt.match, m)
+			}
+		})
+	}
+}
+
+func receive(t *testing.T, ch <-chan string, want string) {
+	t.Helper()
+	select {
+	case got := <-ch:
+		if got != want {
+			t.Errorf("received %s; want %s", got, want)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("timeout waiting for %s", want)
+	}
+}
+
+func TestPublisherTopics(t *testing.T) {
+	p := NewPublisher[string](10)
+	all := make(chan string)
+	files := make(chan string)
+	p.Register(all)
+	p.RegisterTopic("file.*", files, SubscribeOptions{Buffer: 10, Policy: Block})
+	p.SubmitTopic("theme.changed", "dark")
+	p.SubmitTopic("file.saved", "a.txt")
+	receive(t, all, "dark")
+	receive(t, all, "a.txt")
+	receive(t, files, "a.txt")
+	p.Unregister(files)
+	time.Sleep(20 * time.Millisecond)
+	if n := p.Metrics().Subscribers; n != 1 {
+		t.Errorf("Subscribers = %d; want 1", n)
+	}
+	_ = p.Close()
+}
+
+func TestPublisherDropPolicy(t *testing.T) {
+	var tests = []struct {
+		name   string
+		policy OverflowPolicy
+		want   string
+	}{
+		{"oldest", DropOldest, "3"},
+		{"newest", DropNewest, "2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPublisher[string](10)
+			ch := make(chan string)
+			p.RegisterTopic(AllTopics, ch, SubscribeOptions{Buffer: 1, Policy: tt.policy})
+			// the pump holds one message while blocked on ch; the buffer holds one more.
+			p.Submit("1")
+			time.Sleep(20 * time.Millisecond)
+			p.Submit("2")
+			p.Submit("3")
+			time.Sleep(20 * time.Millisecond)
+			receive(t, ch, "1")
+			receive(t, ch, tt.want)
+			if d := p.Metrics().Dropped; d != 1 {
+				t.Errorf("Dropped = %d; want 1", d)
+			}
+			_ = p.Close()
+		})
+	}
+}
+
+func TestPublisherReplay(t *testing.T) {
+	p := NewReplayPublisher[string](10, 2)
+	p.Submit("1")
+	p.Submit("2")
+	p.Submit("3")
+	time.Sleep(20 * time.Millisecond)
+	ch := make(chan string)
+	p.RegisterTopic(AllTopics, ch, SubscribeOptions{Buffer: 5, Replay: true})
+	receive(t, ch, "2")
+	receive(t, ch, "3")
+	_ = p.Close()
+}
+
+func TestPublisherClose(t *testing.T) {
+	p := NewPublisher[string](10)
+	ch := make(chan string)
+	_ = p.Register(ch)
+	got := make(chan int)
+	go func() {
+		n := 0
+		for range ch {
+			n++
+		}
+		got <- n
+	}()
+	_ = p.Submit("1")
+	_ = p.Submit("2")
+	if err := p.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+	if n := <-got; n != 2 {
+		t.Errorf("drained %d; want 2", n)
+	}
+	if err := p.Submit("3"); err != ErrPublisherClosed {
+		t.Errorf("Submit after Close = %v; want %v", err, ErrPublisherClosed)
+	}
+	if err := p.Register(make(chan string)); err != ErrPublisherClosed {
+		t.Errorf("Register after Close = %v; want %v", err, ErrPublisherClosed)
+	}
+	if err := p.Close(); err != ErrPublisherClosed {
+		t.Errorf("second Close = %v; want %v", err, ErrPublisherClosed)
+	}
+}
+
+func TestPublisherCloseContext(t *testing.T) {
+	p := NewPublisher[string](10)
+	ch := make(chan string) // never read
+	_ = p.Register(ch)
+	_ = p.Submit("1")
+	_ = p.Submit("2")
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := p.CloseContext(ctx, CloseDrain); err != context.DeadlineExceeded {
+		t.Errorf("CloseContext = %v; want %v", err, context.DeadlineExceeded)
+	}
+	if _, ok := <-ch; ok {
+		t.Error("subscriber channel not closed")
+	}
+}
+
+func TestPublisherCloseStalled(t *testing.T) {
+	defer func(d time.Duration) { PublisherCloseTimeout = d }(PublisherCloseTimeout)
+	PublisherCloseTimeout = 50 * time.Millisecond
+	p := NewPublisher[string](10)
+	ch, _ := p.Subscribe(context.Background(), AllTopics, SubscribeOptions{Buffer: 1}) // never read
+	_ = p.Submit("1")
+	_ = p.Submit("2")
+	done := make(chan error)
+	go func() { done <- p.Close() }()
+	select {
+	case err := <-done:
+		if err != context.DeadlineExceeded {
+			t.Errorf("Close = %v; want %v", err, context.DeadlineExceeded)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close blocked by a stalled subscriber")
+	}
+	if _, ok := <-ch; ok {
+		t.Error("subscriber channel not closed")
+	}
+}
+
+func TestPublisherSubscribe(t *testing.T) {
+	p := NewPublisher[string](10)
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, _ := p.Subscribe(ctx, "file.#", SubscribeOptions{Buffer: 5})
+	other, unsubscribe := p.Subscribe(context.Background(), AllTopics, SubscribeOptions{Buffer: 5})
+	_ = p.SubmitTopic("file.saved", "a.txt")
+	receive(t, ch, "a.txt")
+	receive(t, other, "a.txt")
+	cancel()
+	if _, ok := <-ch; ok {
+		t.Error("channel open after ctx cancelled")
+	}
+	unsubscribe()
+	unsubscribe()
+	if _, ok := <-other; ok {
+		t.Error("channel open after unsubscribe")
+	}
+	_ = p.Close()
+	closed, _ := p.Subscribe(context.Background(), AllTopics, SubscribeOptions{})
+	if _, ok := <-closed; ok {
+		t.Error("channel open after Close")
+	}
+}
+
+func TestPublisherUnregisterStalled(t *testing.T) {
+	p := NewPublisher[string](10)
+	defer func() { _ = p.CloseContext(context.Background(), CloseDiscard) }()
+	stalled := make(chan string) // never read
+	_ = p.Register(stalled)
+	ctx, cancel := context.WithCancel(context.Background())
+	blocked, _ := p.Subscribe(ctx, AllTopics, SubscribeOptions{Buffer: 1, Policy: Block}) // not read until cancelled
+	for _, m := range []string{"1", "2", "3", "4"} {
+		_ = p.Submit(m)
+	}
+	time.Sleep(20 * time.Millisecond) // run is held up by the Block subscribers
+	done := make(chan error)
+	go func() { done <- p.Unregister(stalled) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Error("Unregister:", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Unregister blocked by a stalled subscriber")
+	}
+	cancel()
+	deadline := time.After(2 * time.Second)
+	for open := true; open; {
+		select {
+		case _, open = <-blocked:
+		case <-deadline:
+			t.Fatal("channel open after ctx cancelled")
+		}
+	}
+	if n := p.Metrics().Subscribers; n != 0 {
+		t.Errorf("Subscribers = %d; want 0", n)
+	}
+	live, _ := p.Subscribe(context.Background(), AllTopics, SubscribeOptions{Buffer: 5})
+	_ = p.Submit("5")
+	receive(t, live, "5")
+}
@@ -0,0 +1,46 @@
+package misc
+
+/*
+
+  File:    queue.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: generic queue implementation. FIFO
+*/
+
+type Queue[T any] struct {
+	items Deque[T]
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func NewQueue[T any]() *Queue[T] {
+	return &Queue[T]{}
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func (q *Queue[T]) Count() int {
+	return q.items.Count()
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func (q *Queue[T]) Push(item T) {
+	q.items.PushBack(item)
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func (q *Queue[T]) Peek() (T, bool) {
+	return q.items.PeekFront()
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func (q *Queue[T]) Pop() (T, bool) {
+	return q.items.PopFront()
+}
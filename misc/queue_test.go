@@ -0,0 +1,34 @@
+package misc
+
+import "testing"
+
+/*
+
+  File:    queue_test.go
+  Author:  Bob Shofner
+
+*/
+
+func TestQueue(t *testing.T) {
+	q := NewQueue[string]()
+	q.Push("item 1")
+	q.Push("item 2")
+	q.Push("item 3")
+	item, b := q.Peek()
+	if !b || item != "item 1" {
+		t.Errorf("peek; item 1 got = %s", item)
+	}
+	for _, want := range []string{"item 1", "item 2", "item 3"} {
+		item, b = q.Pop()
+		if !b || item != want {
+			t.Errorf("pop; %s got = %s", want, item)
+		}
+	}
+	if n := q.Count(); n != 0 {
+		t.Error("Queue wasn't empty")
+	}
+	item, b = q.Pop()
+	if b || item != "" {
+		t.Errorf("empty pop; got = %s", item)
+	}
+}
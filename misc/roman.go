@@ -3,6 +3,8 @@ package misc
 import (
 	"errors"
 	"fmt"
+	"strings"
+	"unicode"
 )
 
 /*
@@ -19,6 +21,9 @@ import (
 */
 /*
   Description: Roman Numerals.
+
+  Written (RomanStyled) in ASCII or the Unicode forms in the table
+    at the bottom, and read back (ParseRoman) from any of them.
 */
 
 // Paragraph generates a string useful for paragraph rows.
@@ -44,35 +49,249 @@ func Paragraph(num int) (string, error) {
 }
 
 // Roman generates a string of roman numerals - up to 3999.
-// 4000 and greater would require unicode characters 216x thru 218x.
+// 4000 and greater would require unicode characters 216x thru 218x (see RomanStyled).
 //goland:noinspection ALL
 func Roman(num int) (string, error) {
-	var thousands = [...]string{"m", "mm", "mmm"}
-	var hundreds = [...]string{"c", "cc", "ccc", "cd", "d", "dc", "dcc", "dccc", "cm"}
-	var tens = [...]string{"x", "xx", "xxx", "xl", "l", "lx", "lxx", "lxxx", "xc"}
-	var ones = [...]string{"i", "ii", "iii", "iv", "v", "vi", "vii", "viii", "ix"}
-	if num > 3999 {
-		return fmt.Sprintf("*%d*", num), errors.New("Invalid Roman")
+	return RomanStyled(num, RomanLower, RomanStandard)
+}
+
+// RomanStyle selects the characters used to write a Roman numeral.
+type RomanStyle int
+
+const (
+	RomanLower        RomanStyle = iota // ASCII i v x l c d m
+	RomanUpper                          // ASCII I V X L C D M
+	RomanUnicodeUpper                   // U+2160 block. 1 thru 12 are single characters (Ⅻ)
+	RomanUnicodeLower                   // U+2170 block (ⅻ)
+)
+
+// RomanLarge selects how values of 4000 and greater are written.
+type RomanLarge int
+
+const (
+	RomanStandard    RomanLarge = iota // up to 3999 (MMMCMXCIX)
+	RomanVinculum                      // bar (U+0305) multiplies by 1000. up to 3,999,999
+	RomanApostrophus                   // ↀ ↁ ↂ ↇ ↈ. up to 399,999
+)
+
+// vinculum is the combining overline placed after a barred numeral.
+const vinculum = '\u0305'
+
+// romanToken is one numeral: its (upper case) symbol and whether it is barred.
+type romanToken struct {
+	sym rune
+	bar bool
+}
+
+var romanValues = map[rune]int{
+	'I': 1, 'V': 5, 'X': 10, 'L': 50, 'C': 100, 'D': 500, 'M': 1000,
+	'ↀ': 1000, 'ↁ': 5000, 'ↂ': 10000, 'ↇ': 50000, 'ↈ': 100000,
+}
+
+// romanPlaces are the one, five and ten symbols of each decimal place.
+var romanPlaces = [...][3]rune{{'I', 'V', 'X'}, {'X', 'L', 'C'}, {'C', 'D', 'M'}}
+var apostrophusPlaces = [...][3]rune{{'ↀ', 'ↁ', 'ↂ'}, {'ↂ', 'ↇ', 'ↈ'}}
+
+// RomanStyled generates a string of roman numerals in the given style.
+// Zero is the empty string.
+//goland:noinspection GoUnusedExportedFunction
+func RomanStyled(num int, style RomanStyle, large RomanLarge) (string, error) {
+	tokens, err := romanTokens(num, large)
+	if err != nil {
+		return fmt.Sprintf("*%d*", num), err
 	}
-	s := ""
-	k := num / 1000
-	h := num % 1000 / 100
-	t := num % 100 / 10
-	o := num % 10
-	//	log.Printf("     %d %d %d %d\n", k, h, t, o)
-	if k > 0 {
-		s += thousands[k-1]
+	return romanString(tokens, num, style), nil
+}
+
+// romanTokens writes num as numerals.
+func romanTokens(num int, large RomanLarge) ([]romanToken, error) {
+	limit := 3999
+	switch large {
+	case RomanVinculum:
+		limit = 3999999
+	case RomanApostrophus:
+		limit = 399999
+	}
+	if num < 0 || num > limit {
+		return nil, errors.New(fmt.Sprintf("Invalid Roman %d", num))
 	}
-	if h > 0 {
-		s += hundreds[h-1]
+	var tokens []romanToken
+	switch {
+	case large == RomanVinculum && num > 3999:
+		for _, t := range romanDigits(num/1000, romanPlaces[:], 'M') {
+			tokens = append(tokens, romanToken{sym: t.sym, bar: true})
+		}
+		num %= 1000
+	case large == RomanApostrophus:
+		tokens = romanDigits(num/1000, apostrophusPlaces[:], 'ↈ')
+		num %= 1000
 	}
-	if t > 0 {
-		s += tens[t-1]
+	return append(tokens, romanDigits(num, romanPlaces[:], 'M')...), nil
+}
+
+// romanDigits writes num with the places given, then repeats top for the rest.
+func romanDigits(num int, places [][3]rune, top rune) (tokens []romanToken) {
+	var digits []romanToken
+	for _, p := range places {
+		var d []romanToken
+		one, five, ten := romanToken{sym: p[0]}, romanToken{sym: p[1]}, romanToken{sym: p[2]}
+		switch n := num % 10; {
+		case n == 9:
+			d = []romanToken{one, ten}
+		case n >= 5:
+			d = append([]romanToken{five}, repeatToken(one, n-5)...)
+		case n == 4:
+			d = []romanToken{one, five}
+		default:
+			d = repeatToken(one, n)
+		}
+		digits = append(d, digits...)
+		num /= 10
+	}
+	return append(repeatToken(romanToken{sym: top}, num), digits...)
+}
+
+func repeatToken(t romanToken, n int) []romanToken {
+	tokens := make([]romanToken, n)
+	for i := range tokens {
+		tokens[i] = t
+	}
+	return tokens
+}
+
+// romanString renders the tokens of num.
+func romanString(tokens []romanToken, num int, style RomanStyle) string {
+	unicodeBase := rune(0)
+	switch style {
+	case RomanUnicodeUpper:
+		unicodeBase = 0x2160
+	case RomanUnicodeLower:
+		unicodeBase = 0x2170
+	}
+	if unicodeBase != 0 && num >= 1 && num <= 12 && !tokens[0].bar {
+		return string(unicodeBase + rune(num-1))
+	}
+	var b strings.Builder
+	for _, t := range tokens {
+		r := t.sym
+		if r < 0x80 {
+			switch {
+			case unicodeBase != 0:
+				r = unicodeBase + romanUnicodeOffset[r]
+			case style == RomanLower:
+				r = unicode.ToLower(r)
+			}
+		}
+		b.WriteRune(r)
+		if t.bar {
+			b.WriteRune(vinculum)
+		}
+	}
+	return b.String()
+}
+
+// romanUnicodeOffset locates the single numerals in the U+2160 (and U+2170) block.
+var romanUnicodeOffset = map[rune]rune{
+	'I': 0x0, 'V': 0x4, 'X': 0x9, 'L': 0xC, 'C': 0xD, 'D': 0xE, 'M': 0xF,
+}
+
+// ParseRoman reads a Roman numeral in any RomanStyle and RomanLarge form.
+// Strict accepts only the form RomanStyled writes (MCMXCIX).
+// Lenient adds the value of each numeral, less any followed by a larger one,
+// so MDCCCCLXXXXVIIII is 1999, IM is 999 and iij (medieval j) is 3; a trailing
+// period (as from Paragraph) is ignored.
+//goland:noinspection GoUnusedExportedFunction
+func ParseRoman(s string, strict bool) (int, error) {
+	text := strings.TrimSpace(s)
+	if !strict {
+		text = strings.TrimSuffix(text, ".")
+	}
+	tokens, ok := parseRomanTokens(text, strict)
+	if !ok || len(tokens) == 0 {
+		return 0, errors.New(fmt.Sprintf("invalid Roman numeral %q", s))
+	}
+	num := 0
+	for i, t := range tokens {
+		v := t.value()
+		if i+1 < len(tokens) && v < tokens[i+1].value() {
+			num -= v
+		} else {
+			num += v
+		}
+	}
+	if !strict {
+		if num < 1 {
+			return 0, errors.New(fmt.Sprintf("invalid Roman numeral %q", s))
+		}
+		return num, nil
+	}
+	for _, large := range []RomanLarge{RomanStandard, RomanVinculum, RomanApostrophus} {
+		if canon, err := romanTokens(num, large); err == nil && num > 0 && equalTokens(canon, tokens) {
+			return num, nil
+		}
+	}
+	return 0, errors.New(fmt.Sprintf("Roman numeral %q is not in standard form", s))
+}
+
+func (t romanToken) value() int {
+	if t.bar {
+		return romanValues[t.sym] * 1000
+	}
+	return romanValues[t.sym]
+}
+
+// parseRomanTokens splits text into numerals, expanding the single character forms (Ⅻ).
+func parseRomanTokens(text string, strict bool) (tokens []romanToken, ok bool) {
+	for _, r := range text {
+		switch {
+		case r == vinculum:
+			if len(tokens) == 0 || tokens[len(tokens)-1].bar {
+				return nil, false
+			}
+			tokens[len(tokens)-1].bar = true
+			continue
+		case r >= 0x2160 && r <= 0x216B, r >= 0x2170 && r <= 0x217B:
+			t, _ := romanTokens(int(r&0xF)+1, RomanStandard)
+			tokens = append(tokens, t...)
+			continue
+		case r >= 0x216C && r <= 0x217F:
+			r = rune("LCDM"[r&0x3])
+		case !strict && (r == 'j' || r == 'J'):
+			r = 'I'
+		case r < 0x80:
+			r = unicode.ToUpper(r)
+		}
+		if _, ok = romanValues[r]; !ok {
+			return nil, false
+		}
+		tokens = append(tokens, romanToken{sym: r})
+	}
+	return tokens, true
+}
+
+func equalTokens(a, b []romanToken) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ParagraphPadded is Paragraph with any width (0 is no padding) and style, up to 3999.
+//goland:noinspection GoUnusedExportedFunction
+func ParagraphPadded(num int, width int, style RomanStyle) (string, error) {
+	if num < 1 {
+		return fmt.Sprintf("*%*d.", width-1, num), errors.New("Invalid Roman")
 	}
-	if o > 0 {
-		s += ones[o-1]
+	s, err := RomanStyled(num, style, RomanStandard)
+	if err != nil {
+		return fmt.Sprintf("*%*d.", width-1, num), err
 	}
-	return s, nil
+	return fmt.Sprintf("%*s.", width, s), nil
 }
 
 /*
@@ -0,0 +1,102 @@
+package misc
+
+import (
+	"fmt"
+	"testing"
+)
+
+/*
+
+  File:    roman_test.go
+  Author:  Bob Shofner
+
+*/
+
+func TestRomanStyled(t *testing.T) {
+	var tests = []struct {
+		num   int
+		style RomanStyle
+		large RomanLarge
+		want  string
+	}{
+		{1999, RomanLower, RomanStandard, "mcmxcix"},
+		{3999, RomanUpper, RomanStandard, "MMMCMXCIX"},
+		{12, RomanUnicodeUpper, RomanStandard, "Ⅻ"},
+		{4, RomanUnicodeLower, RomanStandard, "ⅳ"},
+		{14, RomanUnicodeUpper, RomanStandard, "ⅩⅠⅤ"},
+		{2024, RomanUnicodeLower, RomanStandard, "ⅿⅿⅹⅹⅰⅴ"},
+		{4000, RomanUpper, RomanVinculum, "I̅V̅"},
+		{10001, RomanUpper, RomanVinculum, "X̅I"},
+		{3999, RomanUpper, RomanVinculum, "MMMCMXCIX"},
+		{2024, RomanUpper, RomanApostrophus, "ↀↀXXIV"},
+		{49999, RomanLower, RomanApostrophus, "ↂↇↀↂcmxcix"},
+		{300000, RomanUpper, RomanApostrophus, "ↈↈↈ"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d,%d,%d", tt.num, tt.style, tt.large), func(t *testing.T) {
+			s, err := RomanStyled(tt.num, tt.style, tt.large)
+			if err != nil || s != tt.want {
+				t.Errorf("Expected %s: got %s, %v", tt.want, s, err)
+			}
+			n, err := ParseRoman(s, true)
+			if err != nil || n != tt.num {
+				t.Errorf("Parse %s = %d, %v; want %d", s, n, err, tt.num)
+			}
+		})
+	}
+	if _, err := RomanStyled(4000, RomanUpper, RomanStandard); err == nil {
+		t.Error("Expected an error for 4000")
+	}
+	if s, err := Roman(1984); err != nil || s != "mcmlxxxiv" {
+		t.Errorf("Roman(1984) = %s, %v", s, err)
+	}
+}
+
+func TestParseRoman(t *testing.T) {
+	var tests = []struct {
+		text   string
+		strict bool
+		want   int // 0 = error
+	}{
+		{"MCMXCIX", true, 1999},
+		{" mcmxcix ", true, 1999},
+		{"MDCCCCLXXXXVIIII", true, 0},
+		{"MDCCCCLXXXXVIIII", false, 1999},
+		{"IM", true, 0},
+		{"IM", false, 999},
+		{"iij", false, 3},
+		{"iij", true, 0},
+		{"xiv.", false, 14},
+		{"xiv.", true, 0},
+		{"ⅯⅯⅩⅩⅣ", true, 2024},
+		{"I̅̅", false, 0},
+		{"", false, 0},
+		{"MCMZ", false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s,%t", tt.text, tt.strict), func(t *testing.T) {
+			n, err := ParseRoman(tt.text, tt.strict)
+			if tt.want == 0 {
+				if err == nil {
+					t.Errorf("Expected an error: got %d", n)
+				}
+			} else if err != nil || n != tt.want {
+				t.Errorf("Expected %d: got %d, %v", tt.want, n, err)
+			}
+		})
+	}
+}
+
+func TestParagraphPadded(t *testing.T) {
+	s, err := ParagraphPadded(14, 8, RomanUpper)
+	if err != nil || s != "     XIV." {
+		t.Errorf("Expected %q: got %q, %v", "     XIV.", s, err)
+	}
+	s, _ = ParagraphPadded(14, 6, RomanLower)
+	if p, _ := Paragraph(14); s != p {
+		t.Errorf("Expected Paragraph's %q: got %q", p, s)
+	}
+	if _, err = ParagraphPadded(0, 6, RomanLower); err == nil {
+		t.Error("Expected an error for 0")
+	}
+}
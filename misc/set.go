@@ -1,5 +1,7 @@
 package misc
 
+import "encoding/json"
+
 /*
 
   File:    set.go
@@ -14,54 +16,310 @@ package misc
 */
 /*
   Description: generic set implementation. Index is insertion order (1 relqtive).
+
+  A map holds each item's slot in the items slice, so Has and Add are
+    O(1). A removed item leaves a stale slot behind (its map entry is
+    gone, or points elsewhere once re-added). A Fenwick tree counts the
+    live slots, so the index of a slot (Contains) and the slot of an index
+    (Get, Remove) are O(log n) however many were removed. The stale slots
+    are compacted once they are most of the slice.
+  The algebra (Union etc.) returns a new Set, in the insertion order of s
+    followed by other.
+  A Set marshals to (and from) a JSON array.
 */
 
 type Set[T comparable] struct {
 	items []T
+	index map[T]int // slot in items
+	ranks []int     // Fenwick tree of the live slots
+	stale int       // slots not in index
+	head  int       // every slot before head is stale
 }
 
 //goland:noinspection GoUnusedExportedFunction
 func NewSet[T comparable]() *Set[T] {
-	return &Set[T]{nil}
+	return &Set[T]{index: make(map[T]int)}
+}
+
+// NewSetOf makes a Set of items (duplicates dropped).
+//goland:noinspection GoUnusedExportedFunction
+func NewSetOf[T comparable](items ...T) *Set[T] {
+	s := &Set[T]{index: make(map[T]int, len(items))}
+	for _, t := range items {
+		s.Add(t)
+	}
+	return s
 }
 
 //goland:noinspection GoUnusedExportedFunction
 func (s *Set[T]) Count() int {
-	return len(s.items)
+	return len(s.index)
 }
 
+// Contains returns the index of t, or 0 if not in the set.
 //goland:noinspection GoUnusedExportedFunction
 func (s *Set[T]) Contains(t T) int {
-	for ix := 0; ix < len(s.items); ix++ {
-		if s.items[ix] == t {
-			return ix + 1
-		}
+	slot, ok := s.index[t]
+	if !ok {
+		return 0
 	}
-	return 0
+	return s.prefix(slot + 1)
+}
+
+// Has reports whether t is in the set.
+//goland:noinspection GoUnusedExportedFunction
+func (s *Set[T]) Has(t T) bool {
+	_, ok := s.index[t]
+	return ok
 }
 
 //goland:noinspection GoUnusedExportedFunction
 func (s *Set[T]) Add(t T) {
-	if s.Contains(t) == 0 {
+	if s.index == nil {
+		s.index = make(map[T]int)
+	}
+	if _, ok := s.index[t]; !ok {
+		s.index[t] = len(s.items)
 		s.items = append(s.items, t)
+		s.grow()
 	}
 }
 
+// Remove deletes the item at index ix.
 //goland:noinspection GoUnusedExportedFunction
 func (s *Set[T]) Remove(ix int) {
-	ix--
-	if ix > -1 && ix < len(s.items) {
-		s.items = append(s.items[:ix], s.items[ix+1:]...)
+	if t, ok := s.Get(ix); ok {
+		s.Delete(t)
 	}
 	return
 }
 
+// Delete removes t, reporting whether it was in the set.
+//goland:noinspection GoUnusedExportedFunction
+func (s *Set[T]) Delete(t T) bool {
+	slot, ok := s.index[t]
+	if !ok {
+		return false
+	}
+	s.drop(slot)
+	return true
+}
+
 //goland:noinspection GoUnusedExportedFunction
 func (s *Set[T]) Get(ix int) (t T, b bool) {
-	ix--
-	if ix > -1 && ix < len(s.items) {
-		t = s.items[ix]
+	if ix > 0 && ix <= len(s.index) {
+		t = s.items[s.find(ix)]
 		b = true
 	}
 	return
 }
+
+// Each calls f with each index and item, in order, until f returns false.
+// f must not change the set.
+//goland:noinspection GoUnusedExportedFunction
+func (s *Set[T]) Each(f func(ix int, t T) bool) {
+	ix := 0
+	for slot, t := range s.items {
+		if s.live(slot) {
+			ix++
+			if !f(ix, t) {
+				return
+			}
+		}
+	}
+}
+
+// Items returns a copy of the items, in order.
+//goland:noinspection GoUnusedExportedFunction
+func (s *Set[T]) Items() []T {
+	s.compact()
+	items := make([]T, len(s.items))
+	copy(items, s.items)
+	return items
+}
+
+//goland:noinspection GoUnusedExportedFunction
+func (s *Set[T]) Clone() *Set[T] {
+	c := &Set[T]{items: make([]T, 0, len(s.index)), index: make(map[T]int, len(s.index))}
+	s.Each(func(_ int, t T) bool {
+		c.Add(t)
+		return true
+	})
+	return c
+}
+
+// Union is the items in s or other.
+//goland:noinspection GoUnusedExportedFunction
+func (s *Set[T]) Union(other *Set[T]) *Set[T] {
+	u := s.Clone()
+	other.Each(func(_ int, t T) bool {
+		u.Add(t)
+		return true
+	})
+	return u
+}
+
+// Intersection is the items in both s and other.
+//goland:noinspection GoUnusedExportedFunction
+func (s *Set[T]) Intersection(other *Set[T]) *Set[T] {
+	return s.filter(func(t T) bool { return other.Has(t) })
+}
+
+// Difference is the items in s but not other.
+//goland:noinspection GoUnusedExportedFunction
+func (s *Set[T]) Difference(other *Set[T]) *Set[T] {
+	return s.filter(func(t T) bool { return !other.Has(t) })
+}
+
+// SymmetricDifference is the items in s or other, but not both.
+//goland:noinspection GoUnusedExportedFunction
+func (s *Set[T]) SymmetricDifference(other *Set[T]) *Set[T] {
+	d := s.Difference(other)
+	other.Each(func(_ int, t T) bool {
+		if !s.Has(t) {
+			d.Add(t)
+		}
+		return true
+	})
+	return d
+}
+
+// IsSubset reports whether every item of s is in other.
+//goland:noinspection GoUnusedExportedFunction
+func (s *Set[T]) IsSubset(other *Set[T]) bool {
+	if s.Count() > other.Count() {
+		return false
+	}
+	subset := true
+	s.Each(func(_ int, t T) bool {
+		subset = other.Has(t)
+		return subset
+	})
+	return subset
+}
+
+func (s *Set[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Items())
+}
+
+// UnmarshalJSON replaces the contents of s with a JSON array.
+func (s *Set[T]) UnmarshalJSON(b []byte) error {
+	var items []T
+	if err := json.Unmarshal(b, &items); err != nil {
+		return err
+	}
+	*s = Set[T]{index: make(map[T]int, len(items))}
+	for _, t := range items {
+		s.Add(t)
+	}
+	return nil
+}
+
+func (s *Set[T]) filter(keep func(T) bool) *Set[T] {
+	f := NewSet[T]()
+	s.Each(func(_ int, t T) bool {
+		if keep(t) {
+			f.Add(t)
+		}
+		return true
+	})
+	return f
+}
+
+// live reports whether the slot holds a current item.
+func (s *Set[T]) live(slot int) bool {
+	ix, ok := s.index[s.items[slot]]
+	return ok && ix == slot
+}
+
+// compact drops the stale slots.
+func (s *Set[T]) compact() {
+	if s.stale == 0 {
+		return
+	}
+	items := s.items[:0]
+	for slot, t := range s.items {
+		if s.live(slot) {
+			s.index[t] = len(items)
+			items = append(items, t)
+		}
+	}
+	var zero T
+	for ix := len(items); ix < len(s.items); ix++ {
+		s.items[ix] = zero // let go of removed items
+	}
+	s.items = items
+	s.stale = 0
+	s.head = 0
+	s.ranks = s.ranks[:len(items)]
+	for i := range s.ranks { // every slot is live
+		pos := i + 1
+		s.ranks[i] = pos & -pos
+	}
+}
+
+// drop removes the item at slot, compacting once the stale slots are most of the slice.
+func (s *Set[T]) drop(slot int) {
+	var zero T
+	delete(s.index, s.items[slot])
+	s.items[slot] = zero // let go of it
+	s.add(slot, -1)
+	s.stale++
+	if s.stale > 32 && s.stale > len(s.items)/2 {
+		s.compact()
+	}
+}
+
+// grow adds the last slot (live) to the tree.
+func (s *Set[T]) grow() {
+	pos := len(s.ranks) + 1
+	s.ranks = append(s.ranks, 1+s.prefix(pos-1)-s.prefix(pos-pos&-pos))
+}
+
+// add adds d to the count of slot.
+func (s *Set[T]) add(slot, d int) {
+	for pos := slot + 1; pos <= len(s.ranks); pos += pos & -pos {
+		s.ranks[pos-1] += d
+	}
+}
+
+// prefix is the number of live slots among the first n.
+func (s *Set[T]) prefix(n int) (live int) {
+	for pos := n; pos > 0; pos -= pos & -pos {
+		live += s.ranks[pos-1]
+	}
+	return
+}
+
+// find is the slot of the ix'th (1 relative) live item; ix must be in the set.
+func (s *Set[T]) find(ix int) int {
+	pos := 0
+	step := 1
+	for step*2 <= len(s.ranks) {
+		step *= 2
+	}
+	for ; step > 0; step /= 2 {
+		if pos+step <= len(s.ranks) && s.ranks[pos+step-1] < ix {
+			pos += step
+			ix -= s.ranks[pos-1]
+		}
+	}
+	return pos
+}
+
+// takeFirst removes and returns the first item. Taking every item one by one is O(n):
+// the slots taken are skipped by head, and compacted only once they are most of the slice.
+func (s *Set[T]) takeFirst() (t T, b bool) {
+	for ; s.head < len(s.items); s.head++ {
+		if s.live(s.head) {
+			t, b = s.items[s.head], true
+			break
+		}
+	}
+	if !b {
+		return
+	}
+	s.head++
+	s.drop(s.head - 1)
+	return
+}
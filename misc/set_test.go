@@ -1,6 +1,9 @@
 package misc
 
 import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
 	"testing"
 )
 
@@ -47,3 +50,103 @@ func TestSet(t *testing.T) {
 		t.Error("item 1 missing")
 	}
 }
+
+func TestSetDelete(t *testing.T) {
+	set := NewSetOf("a", "b", "c", "d")
+	if !set.Delete("b") || set.Delete("b") {
+		t.Error("Delete b: want true then false")
+	}
+	if set.Has("b") || !set.Has("c") {
+		t.Error("Has after Delete")
+	}
+	if ix := set.Contains("d"); ix != 3 {
+		t.Errorf("d index = %d; want 3", ix)
+	}
+	set.Add("b")
+	set.Remove(1)
+	if got := fmt.Sprint(set.Items()); got != "[c d b]" {
+		t.Errorf("Items = %s; want [c d b]", got)
+	}
+	var seen []string
+	set.Each(func(ix int, s string) bool {
+		seen = append(seen, fmt.Sprintf("%d%s", ix, s))
+		return ix < 2
+	})
+	if got := fmt.Sprint(seen); got != "[1c 2d]" {
+		t.Errorf("Each = %s; want [1c 2d]", got)
+	}
+}
+
+func TestSetAlgebra(t *testing.T) {
+	a := NewSetOf(1, 2, 3, 4)
+	b := NewSetOf(3, 4, 5)
+	var tests = []struct {
+		name string
+		set  *Set[int]
+		want string
+	}{
+		{"Union", a.Union(b), "[1 2 3 4 5]"},
+		{"Intersection", a.Intersection(b), "[3 4]"},
+		{"Difference", a.Difference(b), "[1 2]"},
+		{"SymmetricDifference", a.SymmetricDifference(b), "[1 2 5]"},
+		{"Clone", a.Clone(), "[1 2 3 4]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fmt.Sprint(tt.set.Items()); got != tt.want {
+				t.Errorf("got %s; want %s", got, tt.want)
+			}
+		})
+	}
+	if a.IsSubset(b) || !NewSetOf(4, 3).IsSubset(b) || !NewSet[int]().IsSubset(a) {
+		t.Error("IsSubset")
+	}
+}
+
+func TestSetJSON(t *testing.T) {
+	set := NewSetOf("x", "y", "z")
+	set.Delete("y")
+	b, err := json.Marshal(set)
+	if err != nil || string(b) != `["x","z"]` {
+		t.Errorf("Marshal = %s, %v", b, err)
+	}
+	var back Set[string]
+	if err = json.Unmarshal([]byte(`["z","x","z"]`), &back); err != nil {
+		t.Fatal(err)
+	}
+	if back.Count() != 2 || back.Contains("x") != 2 {
+		t.Errorf("Unmarshal = %v", back.Items())
+	}
+}
+
+// TestSetIndexes checks Contains and Get against a plain slice while items come and go.
+func TestSetIndexes(t *testing.T) {
+	set := NewSet[int]()
+	model := make([]int, 0)
+	r := rand.New(rand.NewSource(1))
+	for step := 0; step < 5000; step++ {
+		n := r.Intn(200)
+		if r.Intn(3) == 0 {
+			set.Delete(n)
+			for i, m := range model {
+				if m == n {
+					model = append(model[:i], model[i+1:]...)
+					break
+				}
+			}
+		} else if !set.Has(n) {
+			set.Add(n)
+			model = append(model, n)
+		}
+		if set.Count() != len(model) {
+			t.Fatalf("step %d: Count = %d; want %d", step, set.Count(), len(model))
+		}
+		ix := r.Intn(len(model) + 2)
+		if got, ok := set.Get(ix); ok != (ix > 0 && ix <= len(model)) || ok && got != model[ix-1] {
+			t.Fatalf("step %d: Get(%d) = %d %t", step, ix, got, ok)
+		}
+		if ok := ix > 0 && ix <= len(model); ok && set.Contains(model[ix-1]) != ix {
+			t.Fatalf("step %d: Contains(%d) = %d; want %d", step, model[ix-1], set.Contains(model[ix-1]), ix)
+		}
+	}
+}
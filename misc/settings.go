@@ -0,0 +1,544 @@
+package misc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+/*
+
+  File:    settings.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: Settings of an application, kept in a file (normally under Sys.ConfigDir).
+
+  Keys are words separated by '.' ("window.width"). A key not set has
+    its default (SettingsOptions.Defaults). The typed getters (String,
+    Bool, Int, Float, Duration, Strings) convert what they can, and
+    return the zero value for what they can not.
+  Every Set saves the file: written to a temporary file, the old file
+    copied to name.bak, then renamed over it, so there is always a
+    file. A file that will not decode is replaced by its backup.
+  The schema version is kept as "_version". A file older than
+    SettingsOptions.Version is upgraded by each migration in turn.
+  Every change (by Set, Reset or a reload) is published, with the key
+    as the topic, to the subscribers of Publisher.
+  With SettingsOptions.Poll the file is checked for changes made by
+    others (another instance, an editor) and reloaded.
+  The codec is JSON; another format (TOML ...) is a SettingsCodec.
+*/
+
+// SettingsVersionKey is the key of the schema version.
+const SettingsVersionKey = "_version"
+
+// SettingsCodec encodes and decodes the settings file.
+type SettingsCodec interface {
+	Ext() string // file extension (".json")
+	Marshal(values map[string]any) ([]byte, error)
+	Unmarshal(b []byte) (map[string]any, error)
+}
+
+// JSONSettings is the JSON codec.
+var JSONSettings SettingsCodec = jsonSettings{}
+
+type jsonSettings struct{}
+
+func (jsonSettings) Ext() string {
+	return ".json"
+}
+func (jsonSettings) Marshal(values map[string]any) ([]byte, error) {
+	return json.MarshalIndent(values, "", "  ")
+}
+func (jsonSettings) Unmarshal(b []byte) (map[string]any, error) {
+	values := make(map[string]any)
+	if err := json.Unmarshal(b, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// SettingsMigration upgrades the values of the version before to its version.
+type SettingsMigration func(values map[string]any) error
+
+// SettingsOptions controls a Settings.
+type SettingsOptions struct {
+	Codec      SettingsCodec             // JSONSettings if nil
+	Defaults   map[string]any            // value of a key not set
+	Version    int                       // schema version
+	Migrations map[int]SettingsMigration // Migrations[v] upgrades version v-1 to v
+	Poll       time.Duration             // how often to check the file for changes. 0 = never
+}
+
+// SettingChange is published for every changed key. New is the default after a Reset.
+type SettingChange struct {
+	Key string
+	Old any
+	New any
+}
+
+// Settings is safe for concurrent use.
+type Settings struct {
+	mu        sync.RWMutex
+	path      string
+	opts      SettingsOptions
+	values    map[string]any
+	stat      os.FileInfo // of the file last read or written
+	publisher Publisher[SettingChange]
+	stop      chan struct{}
+	done      chan struct{}
+	closer    sync.Once
+}
+
+// SettingsPath is the file used for the settings name in dir.
+//goland:noinspection GoUnusedExportedFunction
+func SettingsPath(dir, name string, codec SettingsCodec) string {
+	if codec == nil {
+		codec = JSONSettings
+	}
+	return filepath.Join(dir, name+codec.Ext())
+}
+
+// OpenSettings loads the settings name from dir (if saved before), migrating them
+// to opts.Version. The settings are usable even with an error.
+//goland:noinspection GoUnusedExportedFunction
+func OpenSettings(dir, name string, opts SettingsOptions) (*Settings, error) {
+	if opts.Codec == nil {
+		opts.Codec = JSONSettings
+	}
+	// the defaults as they would be read back, to compare with what is
+	if b, err := opts.Codec.Marshal(opts.Defaults); err == nil {
+		if defaults, err := opts.Codec.Unmarshal(b); err == nil {
+			opts.Defaults = defaults
+		}
+	}
+	s := &Settings{
+		path:      SettingsPath(dir, name, opts.Codec),
+		opts:      opts,
+		values:    map[string]any{SettingsVersionKey: opts.Version},
+		publisher: NewPublisher[SettingChange](64),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	err := s.Reload()
+	if opts.Poll > 0 {
+		go s.watch()
+	} else {
+		close(s.done)
+	}
+	return s, err
+}
+
+// Path is the settings file.
+func (s *Settings) Path() string {
+	return s.path
+}
+
+// Version is the schema version of the values.
+func (s *Settings) Version() int {
+	v, _ := settingInt(s.Get(SettingsVersionKey))
+	return v
+}
+
+// Publisher publishes a SettingChange for every change, with the key as the topic.
+func (s *Settings) Publisher() Publisher[SettingChange] {
+	return s.publisher
+}
+
+// Keys are the keys set (sorted). The defaults and the version are not included.
+func (s *Settings) Keys() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := make([]string, 0, len(s.values))
+	for k := range s.values {
+		if k != SettingsVersionKey {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// IsSet reports whether key has a value of its own (not the default).
+func (s *Settings) IsSet(key string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.values[key]
+	return ok
+}
+
+// Get is the value of key, or its default. nil if neither.
+func (s *Settings) Get(key string) any {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.get(key)
+}
+
+func (s *Settings) get(key string) any {
+	if v, ok := s.values[key]; ok {
+		return v
+	}
+	return s.opts.Defaults[key]
+}
+
+// String is the value of key as a string.
+func (s *Settings) String(key string) string {
+	switch v := s.Get(key).(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Bool is the value of key as a bool ("true", 1 ...).
+func (s *Settings) Bool(key string) bool {
+	switch v := s.Get(key).(type) {
+	case bool:
+		return v
+	case string:
+		b, _ := strconv.ParseBool(v)
+		return b
+	default:
+		f, _ := settingFloat(v)
+		return f != 0
+	}
+}
+
+// Int is the value of key as an int.
+func (s *Settings) Int(key string) int {
+	i, _ := settingInt(s.Get(key))
+	return i
+}
+
+// Float is the value of key as a float64.
+func (s *Settings) Float(key string) float64 {
+	f, _ := settingFloat(s.Get(key))
+	return f
+}
+
+// Duration is the value of key as a time.Duration. A string is parsed ("1m30s"); a number is nanoseconds.
+func (s *Settings) Duration(key string) time.Duration {
+	switch v := s.Get(key).(type) {
+	case time.Duration:
+		return v
+	case string:
+		d, _ := time.ParseDuration(v)
+		return d
+	default:
+		i, _ := settingInt(v)
+		return time.Duration(i)
+	}
+}
+
+// Strings is the value of key as a []string.
+func (s *Settings) Strings(key string) []string {
+	switch v := s.Get(key).(type) {
+	case []string:
+		return append([]string(nil), v...)
+	case []any:
+		ss := make([]string, 0, len(v))
+		for _, e := range v {
+			ss = append(ss, fmt.Sprint(e))
+		}
+		return ss
+	case string:
+		return []string{v}
+	}
+	return nil
+}
+
+// Set key to v and save. v is kept as the codec reads it back (JSON numbers as float64).
+// Setting the value it has does nothing.
+func (s *Settings) Set(key string, v any) error {
+	return s.change(key, v, true)
+}
+
+// Reset key to its default and save.
+func (s *Settings) Reset(key string) error {
+	return s.change(key, nil, false)
+}
+
+func (s *Settings) change(key string, v any, set bool) error {
+	if key == "" || key == SettingsVersionKey {
+		return errors.New(fmt.Sprintf("settings: invalid key %q", key))
+	}
+	if set {
+		// as it would be read back
+		b, err := s.opts.Codec.Marshal(map[string]any{key: v})
+		if err != nil {
+			return err
+		}
+		values, err := s.opts.Codec.Unmarshal(b)
+		if err != nil {
+			return err
+		}
+		v = values[key]
+	}
+	s.mu.Lock()
+	old, had := s.values[key]
+	if set == had && (!set || reflect.DeepEqual(old, v)) {
+		s.mu.Unlock()
+		return nil
+	}
+	old = s.get(key)
+	if set {
+		s.values[key] = v
+	} else {
+		delete(s.values, key)
+	}
+	c := SettingChange{Key: key, Old: old, New: s.get(key)}
+	err := s.save()
+	s.mu.Unlock()
+	s.publish(c)
+	return err
+}
+
+// Save writes the settings file.
+func (s *Settings) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.save()
+}
+
+// save writes a temporary file beside path, copies the old file to .bak and renames. Called with the lock held.
+func (s *Settings) save() error {
+	b, err := s.opts.Codec.Marshal(s.values)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	if err == nil {
+		err = f.Sync()
+	}
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	if err == nil {
+		if old, e := ioutil.ReadFile(s.path); e == nil {
+			err = ioutil.WriteFile(s.path+".bak", old, 0600)
+		}
+	}
+	if err == nil {
+		err = os.Rename(tmp, s.path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	s.stat, _ = os.Stat(s.path)
+	return nil
+}
+
+// Reload reads the settings file (or its backup), publishing what changed.
+// A file read while the settings were saved is dropped; it may be older than what was saved.
+func (s *Settings) Reload() error {
+	s.mu.RLock()
+	last := s.stat
+	s.mu.RUnlock()
+	values, stat, err := s.read(s.path)
+	if err != nil && !os.IsNotExist(err) {
+		if v, _, e := s.read(s.path + ".bak"); e == nil {
+			log.Println("settings", err, "- using the backup")
+			values, err = v, nil
+		}
+	}
+	if values == nil {
+		s.mu.Lock()
+		if s.stat == last {
+			s.stat = stat
+		}
+		s.mu.Unlock()
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	migrated, err := s.migrate(values)
+	s.mu.Lock()
+	if s.stat != last { // saved (or reloaded) since
+		s.mu.Unlock()
+		return nil
+	}
+	changes := s.diff(values)
+	s.values = values
+	s.stat = stat
+	if migrated && err == nil {
+		err = s.save()
+	}
+	s.mu.Unlock()
+	for _, c := range changes {
+		s.publish(c)
+	}
+	return err
+}
+
+func (s *Settings) read(path string) (map[string]any, os.FileInfo, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, stat, err
+	}
+	values, err := s.opts.Codec.Unmarshal(b)
+	if err != nil {
+		return nil, stat, errors.New(fmt.Sprintf("%s: %v", path, err))
+	}
+	return values, stat, nil
+}
+
+// migrate values to opts.Version, reporting whether any migration ran.
+func (s *Settings) migrate(values map[string]any) (bool, error) {
+	version, _ := settingInt(values[SettingsVersionKey])
+	if version > s.opts.Version {
+		return false, errors.New(fmt.Sprintf("settings version %d is newer than %d", version, s.opts.Version))
+	}
+	migrated := version < s.opts.Version
+	for version < s.opts.Version {
+		version++
+		if m := s.opts.Migrations[version]; m != nil {
+			if err := m(values); err != nil {
+				return false, errors.New(fmt.Sprintf("settings migration to version %d: %v", version, err))
+			}
+		}
+		values[SettingsVersionKey] = version
+	}
+	return migrated, nil
+}
+
+// diff are the changes from the current values to values. Called with the lock held.
+func (s *Settings) diff(values map[string]any) []SettingChange {
+	changes := make([]SettingChange, 0)
+	keys := make(map[string]bool)
+	for k := range s.values {
+		keys[k] = true
+	}
+	for k := range values {
+		keys[k] = true
+	}
+	for k := range keys {
+		if k == SettingsVersionKey {
+			continue
+		}
+		old := s.get(k)
+		v, ok := values[k]
+		if !ok {
+			v = s.opts.Defaults[k]
+		}
+		if !reflect.DeepEqual(old, v) {
+			changes = append(changes, SettingChange{Key: k, Old: old, New: v})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+func (s *Settings) publish(c SettingChange) {
+	if err := s.publisher.SubmitTopic(c.Key, c); err != nil && err != ErrPublisherClosed {
+		log.Println("settings publish", c.Key, err)
+	}
+}
+
+// watch reloads the file when it changes, until Close.
+func (s *Settings) watch() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.opts.Poll)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+		stat, err := os.Stat(s.path)
+		if err != nil {
+			continue
+		}
+		s.mu.RLock()
+		last := s.stat
+		s.mu.RUnlock()
+		if last != nil && stat.ModTime().Equal(last.ModTime()) && stat.Size() == last.Size() {
+			continue
+		}
+		if err = s.Reload(); err != nil {
+			log.Println("settings reload", err)
+		}
+	}
+}
+
+// Close stops watching and closes the Publisher, discarding the changes not yet
+// delivered. The settings are saved after every change already.
+func (s *Settings) Close() (err error) {
+	s.closer.Do(func() {
+		close(s.stop)
+		<-s.done
+		err = s.publisher.CloseContext(context.Background(), CloseDiscard)
+	})
+	return
+}
+
+// settingInt converts the numbers (and number strings) a codec may decode to an int.
+func settingInt(v any) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	case string:
+		i, err := strconv.Atoi(n)
+		return i, err == nil
+	}
+	f, ok := settingFloat(v)
+	return int(f), ok
+}
+
+// settingFloat converts the numbers (and number strings) a codec may decode to a float64.
+func settingFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
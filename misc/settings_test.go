@@ -0,0 +1,175 @@
+package misc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+/*
+
+  File:    settings_test.go
+  Author:  Bob Shofner
+
+*/
+
+func TestSettings(t *testing.T) {
+	dir := t.TempDir()
+	opts := SettingsOptions{Defaults: map[string]any{"window.width": 800, "recent": []string{"a"}}, Version: 1}
+	s, err := OpenSettings(dir, "app", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch, _ := s.Publisher().Subscribe(context.Background(), "window.*", SubscribeOptions{Buffer: 4})
+	if s.Int("window.width") != 800 || s.IsSet("window.width") || fmt.Sprint(s.Strings("recent")) != "[a]" {
+		t.Errorf("defaults: width %d recent %v", s.Int("window.width"), s.Strings("recent"))
+	}
+	if err = s.Set("window.width", 1024); err != nil {
+		t.Fatal(err)
+	}
+	_ = s.Set("name", "Bob")
+	_ = s.Set("dark", true)
+	_ = s.Set("poll", "1m30s")
+	select {
+	case c := <-ch:
+		if c.Key != "window.width" || fmt.Sprint(c.Old, c.New) != "800 1024" {
+			t.Errorf("change = %+v", c)
+		}
+	case <-time.After(time.Second):
+		t.Error("no change published")
+	}
+	_ = s.Close()
+	if _, ok := <-ch; ok {
+		t.Error("subscription open after Close")
+	}
+
+	s, err = OpenSettings(dir, "app", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = s.Close() }()
+	if s.Int("window.width") != 1024 || s.String("name") != "Bob" || !s.Bool("dark") ||
+		s.Duration("poll") != 90*time.Second || s.Version() != 1 {
+		t.Errorf("reopened: %v", s.Keys())
+	}
+	if err = s.Reset("window.width"); err != nil || s.Int("window.width") != 800 {
+		t.Errorf("Reset: %d %v", s.Int("window.width"), err)
+	}
+	if s.Set(SettingsVersionKey, 2) == nil {
+		t.Error("Set _version: want an error")
+	}
+	if _, err = os.Stat(s.Path() + ".bak"); err != nil {
+		t.Error("no backup:", err)
+	}
+}
+
+func TestSettingsMigration(t *testing.T) {
+	dir := t.TempDir()
+	path := SettingsPath(dir, "app", nil)
+	if err := os.WriteFile(path, []byte(`{"_version": 1, "width": 640}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	opts := SettingsOptions{Version: 3, Migrations: map[int]SettingsMigration{
+		2: func(values map[string]any) error {
+			values["window.width"] = values["width"]
+			delete(values, "width")
+			return nil
+		},
+		3: func(values map[string]any) error {
+			values["window.height"] = 480
+			return nil
+		},
+	}}
+	s, err := OpenSettings(dir, "app", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = s.Close()
+	if s.Version() != 3 || s.Int("window.width") != 640 || s.Int("window.height") != 480 || s.IsSet("width") {
+		t.Errorf("migrated: version %d %v", s.Version(), s.Keys())
+	}
+	// the migrated file is saved; a corrupt file falls back to the backup
+	if err = os.WriteFile(path, []byte(`{"_version": 3,`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	s, err = OpenSettings(dir, "app", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = s.Close()
+	if s.Int("window.width") != 640 || s.Version() != 3 {
+		t.Errorf("from backup: version %d %v", s.Version(), s.Keys())
+	}
+	opts.Version = 2
+	if _, err = OpenSettings(dir, "app", opts); err == nil {
+		t.Error("newer version: want an error")
+	}
+}
+
+func TestSettingsWatch(t *testing.T) {
+	dir := t.TempDir()
+	s, err := OpenSettings(dir, "app", SettingsOptions{Poll: 5 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = s.Close() }()
+	ch, _ := s.Publisher().Subscribe(context.Background(), AllTopics, SubscribeOptions{Buffer: 4})
+	_ = s.Set("name", "Bob")
+	if err = os.WriteFile(s.Path(), []byte(`{"_version": 0, "name": "Robert", "size": 12}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	// the changes are delivered in any order, name=Bob (of Set) among them
+	heard := make(map[string]bool)
+	for !heard["name=Robert"] || !heard["size=12"] {
+		select {
+		case c := <-ch:
+			heard[fmt.Sprintf("%s=%v", c.Key, c.New)] = true
+		case <-time.After(2 * time.Second):
+			t.Fatalf("heard %v; want the reload", heard)
+		}
+	}
+	if s.Int("size") != 12 || s.String("name") != "Robert" {
+		t.Errorf("reloaded size %d, name %s", s.Int("size"), s.String("name"))
+	}
+}
+
+// racingCodec calls during, once, while a file is decoded.
+type racingCodec struct {
+	SettingsCodec
+	during func()
+}
+
+func (c *racingCodec) Unmarshal(b []byte) (map[string]any, error) {
+	if during := c.during; during != nil {
+		c.during = nil
+		during()
+	}
+	return c.SettingsCodec.Unmarshal(b)
+}
+
+func TestSettingsReloadRace(t *testing.T) {
+	dir := t.TempDir()
+	codec := &racingCodec{SettingsCodec: JSONSettings}
+	s, err := OpenSettings(dir, "app", SettingsOptions{Codec: codec})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = s.Close() }()
+	_ = s.Set("name", "Bob")
+	codec.during = func() { _ = s.Set("name", "Robert") } // saved after the file was read
+	if err = s.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if name := s.String("name"); name != "Robert" {
+		t.Errorf("name = %s after a reload; want Robert", name)
+	}
+	done := make(chan struct{})
+	go func() {
+		_ = s.Close()
+		close(done)
+	}()
+	_ = s.Close()
+	<-done
+}
@@ -1,8 +1,8 @@
 package misc
 
 import (
-	"regexp"
 	"strings"
+	"unicode"
 )
 
 /*
@@ -32,91 +32,107 @@ so that they can be matched despite minor differences in spelling."
 Based on:
 $ go get github.com/umahmood/soundex
 
+The rules are those of the U.S. National Archives (American Soundex):
+  the first letter is kept, the rest coded as digits; letters with the
+  same digit next to each other (the first letter included) are coded
+  once; a vowel (A E I O U Y) between them separates the two, but H or W
+  does not (Ashcraft A261). The code is padded with zeros to 4 characters.
+Accented letters are transliterated first (Ñ as N, Ł as L, ß as SS).
+Surname prefixes (Van, De, O', Mac) written as a separate word may be
+  coded both with and without the prefix (SoundexCodes).
 */
 
 const EmptySoundex = "000"
 
-// soundexMappings soundex consonant mappings
-var soundexMappings = map[string][]string{
-	"1": {"b", "f", "p", "v"},
-	"2": {"c", "g", "j", "k", "q", "s", "x", "z"},
-	"3": {"d", "t"},
-	"4": {"l"},
-	"5": {"m", "n"},
-	"6": {"r"},
+// soundexDigits is the digit of each letter A thru Z. 0 for vowels, H and W.
+const soundexDigits = "01230120022455012623010202"
+
+// SoundexPrefixes are the surname prefixes SoundexCodes may remove.
+var SoundexPrefixes = []string{
+	"VAN", "VON", "DER", "DEN", "DE", "DI", "DA", "DU", "DEL", "DELLA",
+	"LA", "LE", "O", "D", "MAC", "MC",
 }
 
-var soundexTable = make(map[string]string)
+// SoundexCode generates the soundex code for a given word.
+// A word without letters is EmptySoundex.
+//goland:noinspection GoUnusedExportedFunction
+func SoundexCode(word string) string {
+	return soundex(soundexLetters(word))
+}
 
-// transpose creates a lookup table of soundex mappings
-func transpose() {
-	if len(soundexTable) < 1 {
-		soundexTable = make(map[string]string)
-		for val, list := range soundexMappings {
-			for _, char := range list {
-				soundexTable[char] = val
-			}
-		}
+// SoundexCodes generates the soundex code for a given word and, with
+// stripPrefix, also the code without its prefixes (Van der Berg as Berg).
+// The second code is only returned if it differs.
+//goland:noinspection GoUnusedExportedFunction
+func SoundexCodes(word string, stripPrefix bool) []string {
+	codes := []string{SoundexCode(word)}
+	if !stripPrefix {
+		return codes
 	}
-	return
+	parts := strings.FieldsFunc(soundexFold(word), func(r rune) bool {
+		return r < 'A' || r > 'Z'
+	})
+	for len(parts) > 1 && isSoundexPrefix(parts[0]) {
+		parts = parts[1:]
+	}
+	if code := soundex(strings.Join(parts, "")); code != codes[0] {
+		codes = append(codes, code)
+	}
+	return codes
 }
 
-var digit = regexp.MustCompile("[0-9]")
-var ignore = regexp.MustCompile("[hw]*")
-var vowel = regexp.MustCompile("[aeiouy]*")
-var first = regexp.MustCompile("[1-6]")
-
-// SoundexCode generates the soundex code for a given word
-//goland:noinspection GoUnusedExportedFunction
-func SoundexCode(word string) string {
-	// remove numbers and spaces
-	word = digit.ReplaceAllString(word, "")
-	word = strings.Replace(word, " ", "", -1)
-	if word == "" {
+// soundex codes letters, which are A thru Z only.
+func soundex(letters string) string {
+	if letters == "" {
 		return EmptySoundex
 	}
-	// transpose the mappings to create a lookup table for convenience
-	transpose()
-	// convert the word to lower case for consistent lookups
-	word = strings.ToLower(word)
-	// save the first letter of the word
-	firstLetter := string(word[0])
-	// remove all occurrences of 'h' and 'w' except first letter
-	code := firstLetter + ignore.ReplaceAllString(word[1:], "")
-	// replace all consonants (include the first letter) with digits based
-	// on Soundex mapping table
-	val := ""
-	for idx, ch := range code {
-		t := soundexTable[string(ch)]
-		if t == "" {
-			val += string(code[idx])
-		} else {
-			val += t
+	code := []byte{letters[0]}
+	last := soundexDigits[letters[0]-'A']
+	for i := 1; i < len(letters) && len(code) < 4; i++ {
+		c := letters[i]
+		d := soundexDigits[c-'A']
+		switch {
+		case c == 'H' || c == 'W':
+			// do not separate letters with the same digit
+		case d == '0':
+			last = '0'
+		case d != last:
+			code = append(code, d)
+			last = d
 		}
 	}
-	code = val
-	// replace all adjacent same digits with one digit
-	n := strings.Split(code, "")
-	a := 0
-	b := 1
-	for b < len(n) {
-		if n[a] == n[b] {
-			n = append(n[:a], n[a+1:]...)
+	for len(code) < 4 {
+		code = append(code, '0')
+	}
+	return string(code)
+}
+
+// soundexLetters transliterates word to the letters A thru Z.
+func soundexLetters(word string) string {
+	return letters(soundexFold(word), nil)
+}
+
+// soundexFold upper cases word without accents, spelling out letters
+// that have none to remove (Ł as L). Other characters are left.
+func soundexFold(word string) string {
+	var b strings.Builder
+	for _, r := range upperWord(stripMarks(word)) {
+		if f, ok := latinFold[r]; ok {
+			b.WriteString(f)
+		} else if r < unicode.MaxASCII {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte(' ')
 		}
-		a++
-		b++
 	}
-	code = strings.Join(n, "")
-	// remove all occurrences of a, e, i, o, u, y except first letter
-	code = string(code[0]) + vowel.ReplaceAllString(code[1:], "")
-	// if first symbol is a digit replace it with the saved first letter
-	code = first.ReplaceAllString(string(code[0]), firstLetter) + code[1:]
-	// append 3 zeros if result contains less than 3 digits
-	if len(code) <= 3 {
-		code += EmptySoundex
+	return b.String()
+}
+
+func isSoundexPrefix(s string) bool {
+	for _, p := range SoundexPrefixes {
+		if s == p {
+			return true
+		}
 	}
-	// retain the first 4 characters
-	code = code[:4]
-	// convert to upper case and return
-	return strings.ToUpper(code)
+	return false
 }
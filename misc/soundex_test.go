@@ -0,0 +1,85 @@
+package misc
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+/*
+
+  File:    soundex_test.go
+  Author:  Bob Shofner
+
+*/
+
+func TestSoundexCode(t *testing.T) {
+	var tests = []struct {
+		word string
+		want string
+	}{
+		{"Robert", "R163"},
+		{"Rupert", "R163"},
+		{"Rubin", "R150"},
+		{"Ashcraft", "A261"},
+		{"Ashcroft", "A261"},
+		{"Tymczak", "T522"},
+		{"Pfister", "P236"},
+		{"Honeyman", "H555"},
+		{"Lee", "L000"},
+		{"Ñúñez", "N520"},
+		{"Östberg", "O231"},
+		{"Łukasiewicz", "L222"},
+		{"Straße", "S362"},
+		{"O'Brien", "O165"},
+		{"", EmptySoundex},
+		{"123", EmptySoundex},
+	}
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			if code := SoundexCode(tt.word); code != tt.want {
+				t.Errorf("Expected %s: got %s", tt.want, code)
+			}
+		})
+	}
+}
+
+func TestSoundexCodes(t *testing.T) {
+	var tests = []struct {
+		word  string
+		strip bool
+		want  string
+	}{
+		{"Van der Berg", true, "V536,B620"},
+		{"Van der Berg", false, "V536"},
+		{"O'Brien", true, "O165,B650"},
+		{"De la Cruz", true, "D426,C620"},
+		{"Mac Donald", true, "M235,D543"},
+		{"Dean", true, "D500"},
+		{"Van", true, "V500"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			if codes := strings.Join(SoundexCodes(tt.word, tt.strip), ","); codes != tt.want {
+				t.Errorf("Expected %s: got %s", tt.want, codes)
+			}
+		})
+	}
+}
+
+func TestSoundexCodeConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if code := SoundexCode("Müller"); code != "M460" {
+					t.Errorf("SoundexCode(Müller) = %s; want M460", code)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -38,6 +38,8 @@ func Remove(sl StringList, r string) StringList {
 }
 
 // Add appends a string to the end of a []string.
+//
+// Deprecated: use MRUList, which also persists and notifies.
 //goland:noinspection GoUnusedExportedFunction
 func Add(sl StringList, a string, max int) StringList {
 	sl = append([]string{a}, sl...)
@@ -81,6 +83,8 @@ func Prepend(sl StringList, p string) StringList {
 
 // Replace removes a string (if present) and prepends that string to the array.
 // The maximum size of the list is preserved.
+//
+// Deprecated: use MRUList, which also persists and notifies.
 //goland:noinspection GoUnusedExportedFunction
 func Replace(sl StringList, p string, max int) StringList {
 	slr := Remove(sl, p)
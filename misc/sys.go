@@ -2,10 +2,11 @@ package misc
 
 import (
 	"fmt"
-	"io/ioutil"
+	"log"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
 )
 
@@ -22,15 +23,38 @@ import (
 
 */
 /*
-  Description:
+  Description: System wide parameters and the directories of an application.
+
+  Each directory is created (0700) on first use of GetSys:
+                linux (XDG)                 darwin                             windows
+    ConfigDir   $XDG_CONFIG_HOME/name       ~/Library/Application Support/name %APPDATA%\name
+    DataDir     $XDG_DATA_HOME/name         ~/Library/Application Support/name %LOCALAPPDATA%\name
+    CacheDir    $XDG_CACHE_HOME/name        ~/Library/Caches/name              %LOCALAPPDATA%\name\cache
+    StateDir    $XDG_STATE_HOME/name        ~/Library/Application Support/name %LOCALAPPDATA%\name\state
+    LogDir      $XDG_STATE_HOME/name/log    ~/Library/Logs/name                %LOCALAPPDATA%\name\log
+  The XDG defaults are ~/.config, ~/.local/share, ~/.cache and ~/.local/state.
+  Any of them may be overridden by an environment variable of the
+    upper cased name: NAME_CONFIG_DIR, NAME_DATA_DIR, NAME_CACHE_DIR,
+    NAME_STATE_DIR and NAME_LOG_DIR (handy for tests).
+  In portable mode everything lives next to the executable (config, data,
+    cache, state and log). Portable mode is on if NAME_PORTABLE is set
+    (to anything but 0 or false), or a file "name.portable" is beside
+    the executable.
+  AppDir is ConfigDir, as it always was.
 */
 
 type Sys struct {
-	ARtype   string
-	OStype   string
-	UserHome string
-	AppDir   string
-	TempDir  string
+	ARtype    string
+	OStype    string
+	UserHome  string
+	AppDir    string // same as ConfigDir
+	ConfigDir string // settings
+	DataDir   string // files the user would miss
+	CacheDir  string // files that can be made again
+	StateDir  string // history, queues, locks ...
+	LogDir    string
+	TempDir   string // removed by Delete
+	Portable  bool   // the directories are next to the executable
 }
 
 var system Sys
@@ -41,28 +65,33 @@ var doOnce sync.Once
 func GetSys(name string) Sys {
 	// Singleton Sys.
 	doOnce.Do(func() {
-		system = Sys{OStype: runtime.GOOS, ARtype: runtime.GOARCH}
-		system.UserHome = userHomeDir()
-		system.AppDir = appDataDir(name)
-		system.TempDir = tempDataDir(name)
+		exe, _ := os.Executable()
+		var err error
+		system, err = newSys(name, runtime.GOOS, os.Getenv, exe)
+		if err != nil {
+			log.Println("sys", err) // the directories are known, if not all usable
+		}
 	})
 	return system
 }
 
 // "toString"
 func (sys Sys) String() string {
-	return fmt.Sprintf("ARCH %s, OS %s, HOME %s, APPDATA %s, Temp %s", sys.ARtype,
-		sys.OStype, sys.UserHome, sys.AppDir, sys.TempDir)
+	s := fmt.Sprintf("ARCH %s, OS %s, HOME %s, CONFIG %s, DATA %s, CACHE %s, STATE %s, LOG %s, Temp %s",
+		sys.ARtype, sys.OStype, sys.UserHome, sys.ConfigDir, sys.DataDir, sys.CacheDir, sys.StateDir, sys.LogDir, sys.TempDir)
+	if sys.Portable {
+		s += ", portable"
+	}
+	return s
 }
 
 // Delete the temp files amd directory
 //goland:noinspection GoUnusedExportedFunction
 func (sys Sys) Delete() {
-	_ = os.RemoveAll(system.TempDir)
-	_ = os.Remove(system.TempDir)
+	_ = os.RemoveAll(sys.TempDir)
 }
 
-// Drives enumerates the logical drives / standard places on the system.
+// Drives enumerates the logical drives (windows) / mounted volumes (linux) / standard places on the system.
 //goland:noinspection GoUnusedExportedFunction
 func (sys Sys) Drives() (drives []string, err error) {
 	switch sys.OStype {
@@ -77,62 +106,162 @@ func (sys Sys) Drives() (drives []string, err error) {
 			}
 		}
 	case "linux":
+		volumes, e := Volumes()
+		if e != nil {
+			return []string{"/"}, e
+		}
+		for _, v := range volumes {
+			drives = append(drives, v.MountPoint)
+		}
+	default:
 		dirs := []string{"/", "/etc", "/home", "/media", "/mnt", "/tmp", "/usr"}
 		for _, dir := range dirs {
 			f, err := os.Open(dir)
 			if err == nil {
-				if err == nil {
-					drives = append(drives, dir)
-					_ = f.Close()
-				}
+				drives = append(drives, dir)
+				_ = f.Close()
 			}
 		}
-	default:
-		drives = append(drives, "/")
 	}
 	return
 }
 
-// userHomeDir provides the path to the user's Home directory (windows or other).
-func userHomeDir() string {
-	home, err := os.UserHomeDir()
-	if err == nil {
-		return home
+// newSys finds (and creates) the directories of name, for goos with the environment getenv
+// and the executable exe. The directories of the application are made private (0700);
+// one named by a NAME_..._DIR override is left as it is. The error is the first
+// directory that could not be made, but every directory is tried.
+func newSys(name, goos string, getenv func(string) string, exe string) (Sys, error) {
+	sys := Sys{OStype: goos, ARtype: runtime.GOARCH}
+	sys.UserHome = userHomeDir(goos, getenv)
+	prefix := envPrefix(name)
+	dirs := map[string]*string{"CONFIG": &sys.ConfigDir, "DATA": &sys.DataDir, "CACHE": &sys.CacheDir,
+		"STATE": &sys.StateDir, "LOG": &sys.LogDir}
+	if sys.Portable = isPortable(name, prefix, getenv, exe); sys.Portable {
+		base := filepath.Dir(exe)
+		for kind, dir := range dirs {
+			*dir = filepath.Join(base, strings.ToLower(kind))
+		}
+	} else {
+		platformDirs(&sys, name, goos, getenv)
 	}
-	home = os.Getenv("HOMEDRIVE")
-	if home == "" {
-		panic("Unable to get User Home")
+	var err error
+	for kind, dir := range dirs {
+		override := getenv(prefix + "_" + kind + "_DIR")
+		if override != "" {
+			*dir = override
+		}
+		e := os.MkdirAll(*dir, 0700)
+		if e == nil && override == "" {
+			e = os.Chmod(*dir, 0700) // MkdirAll leaves an existing directory alone
+		}
+		if e != nil && err == nil {
+			err = e
+		}
 	}
-	return home
+	sys.AppDir = sys.ConfigDir
+	sys.TempDir = tempDataDir(name, sys.CacheDir)
+	return sys, err
 }
 
-// appDataDir provides the path to the user's Application directory for (windows or other).
-func appDataDir(name string) string {
-	if configDir, e1 := os.UserConfigDir(); e1 == nil {
-		p := filepath.Join(configDir, name)
-		_, e2 := os.Stat(p)
-		if e2 == nil {
-			return p
-		} else if os.IsNotExist(e2) {
-			if os.MkdirAll(p, os.ModeDir) == nil {
-				return p
-			}
+// platformDirs are the directories of name by the conventions of goos.
+func platformDirs(sys *Sys, name, goos string, getenv func(string) string) {
+	home := sys.UserHome
+	switch goos {
+	case "windows":
+		roaming := getenv("APPDATA")
+		if roaming == "" {
+			roaming = filepath.Join(home, "AppData", "Roaming")
+		}
+		local := getenv("LOCALAPPDATA")
+		if local == "" {
+			local = filepath.Join(home, "AppData", "Local")
+		}
+		sys.ConfigDir = filepath.Join(roaming, name)
+		sys.DataDir = filepath.Join(local, name)
+		sys.CacheDir = filepath.Join(local, name, "cache")
+		sys.StateDir = filepath.Join(local, name, "state")
+		sys.LogDir = filepath.Join(local, name, "log")
+	case "darwin", "ios":
+		support := filepath.Join(home, "Library", "Application Support", name)
+		sys.ConfigDir = support
+		sys.DataDir = support
+		sys.CacheDir = filepath.Join(home, "Library", "Caches", name)
+		sys.StateDir = support
+		sys.LogDir = filepath.Join(home, "Library", "Logs", name)
+	default:
+		sys.ConfigDir = filepath.Join(xdgDir(getenv, "XDG_CONFIG_HOME", home, ".config"), name)
+		sys.DataDir = filepath.Join(xdgDir(getenv, "XDG_DATA_HOME", home, ".local", "share"), name)
+		sys.CacheDir = filepath.Join(xdgDir(getenv, "XDG_CACHE_HOME", home, ".cache"), name)
+		sys.StateDir = filepath.Join(xdgDir(getenv, "XDG_STATE_HOME", home, ".local", "state"), name)
+		sys.LogDir = filepath.Join(sys.StateDir, "log")
+	}
+}
+
+// xdgDir is the XDG variable env, or the default below home. A relative path is invalid (and ignored).
+func xdgDir(getenv func(string) string, env, home string, def ...string) string {
+	if dir := getenv(env); dir != "" && filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(append([]string{home}, def...)...)
+}
+
+// isPortable reports whether NAME_PORTABLE is set, or name.portable is beside exe.
+func isPortable(name, prefix string, getenv func(string) string, exe string) bool {
+	switch strings.ToLower(getenv(prefix + "_PORTABLE")) {
+	case "":
+	case "0", "false", "no", "off":
+		return false
+	default:
+		return true
+	}
+	if exe == "" {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(filepath.Dir(exe), name+".portable"))
+	return err == nil
+}
+
+// envPrefix is name as an environment variable ("my-app" as MY_APP).
+func envPrefix(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
 		}
+		return '_'
+	}, name)
+}
+
+// userHomeDir provides the path to the user's Home directory (windows or other).
+func userHomeDir(goos string, getenv func(string) string) string {
+	env := "HOME"
+	if goos == "windows" {
+		env = "USERPROFILE"
+	}
+	if home := getenv(env); home != "" {
+		return home
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return home
 	}
-	p := filepath.Join(userHomeDir(), name)
-	if os.MkdirAll(p, os.ModeDir) == nil {
-		return p
+	home := getenv("HOMEDRIVE")
+	if home == "" {
+		panic("Unable to get User Home")
 	}
-	panic("Unable to get User AppDataDir")
+	return home + getenv("HOMEPATH")
 }
 
-// tempDataDir provides the path to a Temp directory for (windows or other).
-func tempDataDir(name string) string {
-	tempDir, err := ioutil.TempDir("", name+"*")
+// tempDataDir provides the path to a (new) Temp directory for (windows or other).
+func tempDataDir(name, cacheDir string) string {
+	tempDir, err := os.MkdirTemp("", name+"*")
 	if err == nil {
 		return tempDir
 	}
-	return filepath.Join(userHomeDir(), name, "_temp")
+	tempDir = filepath.Join(cacheDir, "_temp")
+	_ = os.MkdirAll(tempDir, 0700)
+	return tempDir
 }
 
 /*
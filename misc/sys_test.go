@@ -0,0 +1,158 @@
+package misc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+/*
+
+  File:    sys_test.go
+  Author:  Bob Shofner
+
+*/
+
+func testEnv(env map[string]string) func(string) string {
+	return func(key string) string { return env[key] }
+}
+
+func TestSysXDG(t *testing.T) {
+	home := t.TempDir()
+	state := filepath.Join(home, "state")
+	env := testEnv(map[string]string{"HOME": home, "XDG_STATE_HOME": state, "XDG_CACHE_HOME": "relative"})
+	sys, err := newSys("my-app", "linux", env, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sys.Delete()
+	tests := []struct{ got, want string }{
+		{sys.ConfigDir, filepath.Join(home, ".config", "my-app")},
+		{sys.DataDir, filepath.Join(home, ".local", "share", "my-app")},
+		{sys.CacheDir, filepath.Join(home, ".cache", "my-app")}, // a relative XDG_CACHE_HOME is ignored
+		{sys.StateDir, filepath.Join(state, "my-app")},
+		{sys.LogDir, filepath.Join(state, "my-app", "log")},
+		{sys.AppDir, sys.ConfigDir},
+	}
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("dir = %s; want %s", test.got, test.want)
+		}
+	}
+	info, err := os.Stat(sys.LogDir)
+	if err != nil || !info.IsDir() {
+		t.Fatalf("LogDir not created: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0700 {
+		t.Errorf("LogDir permissions = %o; want 700", perm)
+	}
+}
+
+func TestSysPlatforms(t *testing.T) {
+	home := t.TempDir()
+	env := testEnv(map[string]string{"HOME": home, "USERPROFILE": home, "APPDATA": filepath.Join(home, "Roaming")})
+	sys, err := newSys("app", "windows", env, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sys.Delete()
+	if sys.ConfigDir != filepath.Join(home, "Roaming", "app") ||
+		sys.CacheDir != filepath.Join(home, "AppData", "Local", "app", "cache") {
+		t.Errorf("windows: %s", sys)
+	}
+	sys, err = newSys("app", "darwin", env, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sys.Delete()
+	if sys.DataDir != filepath.Join(home, "Library", "Application Support", "app") ||
+		sys.LogDir != filepath.Join(home, "Library", "Logs", "app") {
+		t.Errorf("darwin: %s", sys)
+	}
+}
+
+func TestSysOverrides(t *testing.T) {
+	home := t.TempDir()
+	exe := filepath.Join(home, "bin", "app")
+	config := filepath.Join(home, "elsewhere")
+	env := map[string]string{"HOME": home, "APP_CONFIG_DIR": config}
+	sys, err := newSys("app", "linux", testEnv(env), exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sys.Delete()
+	if sys.Portable || sys.ConfigDir != config || sys.AppDir != config {
+		t.Errorf("override: %s", sys)
+	}
+	// a marker file beside the executable
+	if err = os.MkdirAll(filepath.Dir(exe), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(home, "bin", "app.portable"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	sys, err = newSys("app", "linux", testEnv(env), exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sys.Delete()
+	if !sys.Portable || sys.DataDir != filepath.Join(home, "bin", "data") || sys.ConfigDir != config {
+		t.Errorf("portable: %s", sys)
+	}
+	env["APP_PORTABLE"] = "false"
+	if sys, err = newSys("app", "linux", testEnv(env), exe); err != nil || sys.Portable {
+		t.Errorf("APP_PORTABLE=false: %s %v", sys, err)
+	}
+	sys.Delete()
+}
+
+func TestSysPermissions(t *testing.T) {
+	home := t.TempDir()
+	config := filepath.Join(home, ".config", "app")
+	if err := os.MkdirAll(config, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TMPDIR", filepath.Join(home, "missing")) // no temp directory, so the cache is used
+	sys, err := newSys("app", "linux", testEnv(map[string]string{"HOME": home}), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sys.Delete()
+	info, err := os.Stat(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0700 {
+		t.Errorf("existing ConfigDir permissions = %o; want 700", perm)
+	}
+	if sys.TempDir != filepath.Join(sys.CacheDir, "_temp") {
+		t.Errorf("TempDir = %s; want the cache fallback", sys.TempDir)
+	}
+	if info, err = os.Stat(sys.TempDir); err != nil || !info.IsDir() {
+		t.Errorf("TempDir not created: %v", err)
+	}
+}
+
+func TestSysOverridePermissions(t *testing.T) {
+	home := t.TempDir()
+	documents := filepath.Join(home, "Documents")
+	if err := os.MkdirAll(documents, 0755); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(home, "file")
+	if err := os.WriteFile(file, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{"HOME": home, "APP_DATA_DIR": documents, "APP_CACHE_DIR": filepath.Join(file, "cache")}
+	sys, err := newSys("app", "linux", testEnv(env), "")
+	if err == nil {
+		t.Error("a cache below a file: want an error")
+	}
+	defer sys.Delete()
+	if info, err := os.Stat(documents); err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("override DataDir changed: %v %v", info, err)
+	}
+	if info, err := os.Stat(sys.ConfigDir); err != nil || !info.IsDir() {
+		t.Errorf("ConfigDir not created after an error: %v", err)
+	}
+}
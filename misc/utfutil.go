@@ -4,11 +4,19 @@ import (
 	"bufio"
 	"bytes"
 	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
 	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/encoding/unicode/utf32"
 	"golang.org/x/text/transform"
 	"io"
 	"io/ioutil"
 	"os"
+	"strings"
+	"unicode/utf8"
 )
 
 /*
@@ -28,7 +36,8 @@ import (
 */
 /*
 
-Package utfutil provides methods that make it easy to read data in an UTF-encoding agnostic.
+Package utfutil provides methods that make it easy to read data in an UTF-encoding agnostic,
+and to write it back in the encoding other programs expect.
 
 These functions autodetect UTF BOM and return UTF-8. If no
 BOM is found, a hint is provided as to which encoding to assume.
@@ -40,14 +49,27 @@ utfutil.ReadFile() is a replacement for ioutil.ReadFile().
 utfutil.NewScanner() takes a filename and returns a Scanner.
 utfutil.NewReader() rewraps an existing scanner to make it UTF-encoding agnostic.
 utfutil.BytesReader() takes a []byte and decodes it to UTF-8.
+utfutil.OpenFileDetect() and utfutil.NewReaderDetect() also return the encoding used.
+utfutil.DetectEncoding() guesses the encoding of a sample.
+utfutil.CreateFile() is a replacement for os.Create() that encodes as it writes.
+utfutil.WriteFile() is a replacement for ioutil.WriteFile().
+utfutil.NewWriter() wraps a Writer to encode UTF-8 as it writes.
+
+Besides the UTF encodings, the hints name the legacy code pages still
+used by older tools: Windows-1252, ISO-8859-x, IBM437 (DOS), MacRoman,
+ANSEL (genealogy) and the CJK multibyte encodings. WriteOptions add
+a BOM and/or CR LF line endings.
 
 When there is no BOM, it is impossible to guess correctly 100%
 of the time.  Therefore, the functions take a 2nd parameter of type
 "EncodingHint" where you specify the default encoding for BOM-less
 data.
 
-In the future we'd like to have a hint called AUTO that uses
-uchatdet (or a Go rewrite) to guess.
+The AUTO hint guesses from the first part of the data: valid UTF-8,
+UTF-16 without a BOM (from the pattern of zero bytes), the common
+CJK multibyte encodings (Shift-JIS, EUC-JP, GBK, Big5, EUC-KR; scored
+by how many frequent characters each decoding produces) and, failing
+those, Windows-1252 or ISO-8859-1.
 
 Inspiration: I wrote this after spending half a day trying
 to figure out how to use unicode.BOMOverride.
@@ -67,6 +89,58 @@ const (
 	UTF16LE
 	// UTF16BE indicates the specified encoding.
 	UTF16BE
+	// AUTO detects the encoding from the data.
+	AUTO
+	// CP1252 indicates Windows-1252 (Western European, MS-Windows).
+	CP1252
+	// ISO8859_1 indicates ISO-8859-1 (Latin-1).
+	ISO8859_1
+	// SHIFTJIS indicates the specified encoding (Japanese, MS-Windows).
+	SHIFTJIS
+	// EUCJP indicates the specified encoding (Japanese, Unix).
+	EUCJP
+	// GBK indicates the specified encoding (Simplified Chinese; a superset of GB2312).
+	GBK
+	// BIG5 indicates the specified encoding (Traditional Chinese).
+	BIG5
+	// EUCKR indicates the specified encoding (Korean).
+	EUCKR
+	// UTF32LE indicates the specified encoding.
+	UTF32LE
+	// UTF32BE indicates the specified encoding.
+	UTF32BE
+	// IBM437 indicates the original IBM PC (DOS) code page.
+	IBM437
+	// MACROMAN indicates the classic Mac OS Roman encoding.
+	MACROMAN
+	// ISO8859_2 indicates ISO-8859-2 (Latin-2, Central European).
+	ISO8859_2
+	// ISO8859_3 indicates ISO-8859-3 (Latin-3, South European).
+	ISO8859_3
+	// ISO8859_4 indicates ISO-8859-4 (Latin-4, North European).
+	ISO8859_4
+	// ISO8859_5 indicates ISO-8859-5 (Cyrillic).
+	ISO8859_5
+	// ISO8859_6 indicates ISO-8859-6 (Arabic).
+	ISO8859_6
+	// ISO8859_7 indicates ISO-8859-7 (Greek).
+	ISO8859_7
+	// ISO8859_8 indicates ISO-8859-8 (Hebrew).
+	ISO8859_8
+	// ISO8859_9 indicates ISO-8859-9 (Latin-5, Turkish).
+	ISO8859_9
+	// ISO8859_10 indicates ISO-8859-10 (Latin-6, Nordic).
+	ISO8859_10
+	// ISO8859_13 indicates ISO-8859-13 (Latin-7, Baltic).
+	ISO8859_13
+	// ISO8859_14 indicates ISO-8859-14 (Latin-8, Celtic).
+	ISO8859_14
+	// ISO8859_15 indicates ISO-8859-15 (Latin-9, Latin-1 with the Euro sign).
+	ISO8859_15
+	// ISO8859_16 indicates ISO-8859-16 (Latin-10, South-Eastern European).
+	ISO8859_16
+	// ANSEL indicates ANSI Z39.47, the 8 bit encoding of GEDCOM 5.5 (see ansel.go).
+	ANSEL
 	// WINDOWS indicates that the file came from a MS-Windows system
 	WINDOWS = UTF16LE
 	// POSIX indicates that the file came from Unix or Unix-like systems
@@ -75,6 +149,126 @@ const (
 	HTML5 = UTF8
 )
 
+// String names the encoding.
+func (d EncodingHint) String() string {
+	switch d {
+	case UTF8:
+		return "UTF-8"
+	case UTF16LE:
+		return "UTF-16LE"
+	case UTF16BE:
+		return "UTF-16BE"
+	case AUTO:
+		return "AUTO"
+	case CP1252:
+		return "windows-1252"
+	case ISO8859_1:
+		return "ISO-8859-1"
+	case SHIFTJIS:
+		return "Shift_JIS"
+	case EUCJP:
+		return "EUC-JP"
+	case GBK:
+		return "GBK"
+	case BIG5:
+		return "Big5"
+	case EUCKR:
+		return "EUC-KR"
+	case UTF32LE:
+		return "UTF-32LE"
+	case UTF32BE:
+		return "UTF-32BE"
+	case IBM437:
+		return "IBM437"
+	case MACROMAN:
+		return "macintosh"
+	case ANSEL:
+		return "ANSEL"
+	}
+	if c, ok := isoCharmaps[d]; ok {
+		return c.String()
+	}
+	return "unknown"
+}
+
+// encoding is the x/text encoding for the hint.
+func (d EncodingHint) encoding() encoding.Encoding {
+	switch d {
+	case UTF16LE:
+		return unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+	case UTF16BE:
+		return unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)
+	case CP1252:
+		return charmap.Windows1252
+	case ISO8859_1:
+		return charmap.ISO8859_1
+	case SHIFTJIS:
+		return japanese.ShiftJIS
+	case EUCJP:
+		return japanese.EUCJP
+	case GBK:
+		return simplifiedchinese.GBK
+	case BIG5:
+		return traditionalchinese.Big5
+	case EUCKR:
+		return korean.EUCKR
+	case UTF32LE:
+		return utf32.UTF32(utf32.LittleEndian, utf32.IgnoreBOM)
+	case UTF32BE:
+		return utf32.UTF32(utf32.BigEndian, utf32.IgnoreBOM)
+	case IBM437:
+		return charmap.CodePage437
+	case MACROMAN:
+		return charmap.Macintosh
+	case ANSEL:
+		return anselEncoding{}
+	}
+	if c, ok := isoCharmaps[d]; ok {
+		return c
+	}
+	return unicode.UTF8
+}
+
+var isoCharmaps = map[EncodingHint]*charmap.Charmap{
+	ISO8859_2:  charmap.ISO8859_2,
+	ISO8859_3:  charmap.ISO8859_3,
+	ISO8859_4:  charmap.ISO8859_4,
+	ISO8859_5:  charmap.ISO8859_5,
+	ISO8859_6:  charmap.ISO8859_6,
+	ISO8859_7:  charmap.ISO8859_7,
+	ISO8859_8:  charmap.ISO8859_8,
+	ISO8859_9:  charmap.ISO8859_9,
+	ISO8859_10: charmap.ISO8859_10,
+	ISO8859_13: charmap.ISO8859_13,
+	ISO8859_14: charmap.ISO8859_14,
+	ISO8859_15: charmap.ISO8859_15,
+	ISO8859_16: charmap.ISO8859_16,
+}
+
+// isUnicode reports whether the hint is one of the UTF encodings (which may have a BOM).
+func (d EncodingHint) isUnicode() bool {
+	switch d {
+	case UTF8, UTF16LE, UTF16BE, UTF32LE, UTF32BE:
+		return true
+	}
+	return false
+}
+
+// decoder makes a decoder that abides by a BOM if found. A legacy code page has
+// no BOM; its "ÿþ" is text.
+func (d EncodingHint) decoder() transform.Transformer {
+	if !d.isUnicode() {
+		return d.encoding().NewDecoder()
+	}
+	switch d {
+	case UTF32LE: // BOMOverride would take FF FE 00 00 for UTF-16LE
+		return utf32.UTF32(utf32.LittleEndian, utf32.UseBOM).NewDecoder()
+	case UTF32BE:
+		return utf32.UTF32(utf32.BigEndian, utf32.UseBOM).NewDecoder()
+	}
+	return unicode.BOMOverride(d.encoding().NewDecoder())
+}
+
 // UTFReadCloser describes the utfutil ReadCloser structure.
 type UTFReadCloser interface {
 	Read(p []byte) (n int, err error)
@@ -160,13 +354,21 @@ func (sc scanCloser) Close() error {
 
 // OpenFile is the equivalent of os.Open().
 func OpenFile(name string, d EncodingHint) (UTFReadCloser, error) {
+	rc, _, err := OpenFileDetect(name, d)
+	return rc, err
+}
+
+// OpenFileDetect is OpenFile that also returns the encoding being decoded.
+//goland:noinspection GoUnusedExportedFunction
+func OpenFileDetect(name string, d EncodingHint) (UTFReadCloser, EncodingHint, error) {
 	f, err := os.Open(name)
 	if err != nil {
-		return nil, err
+		return nil, d, err
 	}
 
 	rc := readCloser{file: f}
-	return NewReader(rc, d), nil
+	r, e := NewReaderDetect(rc, d)
+	return r, e, nil
 }
 
 // ReadFile is the equivalent of ioutil.ReadFile()
@@ -198,32 +400,36 @@ func NewScanner(name string, d EncodingHint) (UTFScanCloser, error) {
 
 // NewReader wraps a Reader to decode Unicode to UTF-8 as it reads.
 func NewReader(r io.Reader, d EncodingHint) UTFReadCloser {
-	var decoder *encoding.Decoder
-	switch d {
-	case UTF8:
-		// Make a transformer that assumes UTF-8 but abides by the BOM.
-		decoder = unicode.UTF8.NewDecoder()
-	case UTF16LE:
-		// Make an tranformer that decodes MS-Windows (16LE) UTF files:
-		winutf := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
-		// Make a transformer that is like winutf, but abides by BOM if found:
-		decoder = winutf.NewDecoder()
-	case UTF16BE:
-		// Make an tranformer that decodes UTF-16BE files:
-		utf16be := unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)
-		// Make a transformer that is like utf16be, but abides by BOM if found:
-		decoder = utf16be.NewDecoder()
-	}
+	rc, _ := NewReaderDetect(r, d)
+	return rc
+}
 
-	// Make a Reader that uses utf16bom:
-	if rc, ok := r.(readCloser); ok {
-		rc.reader = transform.NewReader(rc.file, unicode.BOMOverride(decoder))
-		return rc
+// detectSize is how much is examined to guess for AUTO.
+const detectSize = 16 * 1024
+
+// NewReaderDetect is NewReader that also returns the encoding being decoded:
+// for AUTO the one named by a BOM or detected, else d. Only AUTO reads ahead
+// (to detect); with any other hint nothing is read until the first Read.
+func NewReaderDetect(r io.Reader, d EncodingHint) (UTFReadCloser, EncodingHint) {
+	src := r
+	rc, isRC := r.(readCloser)
+	if isRC && rc.file != nil {
+		src = rc.file
+	}
+	if d == AUTO {
+		br := bufio.NewReaderSize(src, detectSize)
+		sample, _ := br.Peek(detectSize)
+		d, _ = DetectEncoding(sample)
+		src = br
 	}
 
-	return readCloser{
-		reader: transform.NewReader(r, unicode.BOMOverride(decoder)),
+	// Make a transformer that assumes the hint but abides by the BOM.
+	reader := transform.NewReader(src, d.decoder())
+	if isRC {
+		rc.reader = reader
+		return rc, d
 	}
+	return readCloser{reader: reader}, d
 }
 
 // BytesReader is a convenience function that takes a []byte and decodes them to UTF-8.
@@ -231,3 +437,287 @@ func NewReader(r io.Reader, d EncodingHint) UTFReadCloser {
 func BytesReader(b []byte, d EncodingHint) io.Reader {
 	return NewReader(bytes.NewReader(b), d)
 }
+
+var bomUTF8 = []byte{0xef, 0xbb, 0xbf}
+var bomUTF16LE = []byte{0xff, 0xfe}
+var bomUTF16BE = []byte{0xfe, 0xff}
+var bomUTF32LE = []byte{0xff, 0xfe, 0, 0}
+var bomUTF32BE = []byte{0, 0, 0xfe, 0xff}
+
+// DetectEncoding guesses the encoding of a sample (the start of the data).
+// bom reports that the encoding was named by a byte order mark.
+//goland:noinspection GoUnusedExportedFunction
+func DetectEncoding(sample []byte) (d EncodingHint, bom bool) {
+	switch { // UTF-32LE first; its BOM starts like UTF-16LE's
+	case bytes.HasPrefix(sample, bomUTF32LE):
+		return UTF32LE, true
+	case bytes.HasPrefix(sample, bomUTF32BE):
+		return UTF32BE, true
+	case bytes.HasPrefix(sample, bomUTF8):
+		return UTF8, true
+	case bytes.HasPrefix(sample, bomUTF16LE):
+		return UTF16LE, true
+	case bytes.HasPrefix(sample, bomUTF16BE):
+		return UTF16BE, true
+	}
+	if d, ok := detectUTF32(sample); ok {
+		return d, false
+	}
+	if d, ok := detectUTF16(sample); ok {
+		return d, false
+	}
+	if validUTF8(sample) {
+		return UTF8, false
+	}
+	if d, ok := detectCJK(sample); ok {
+		return d, false
+	}
+	return detectLatin(sample), false
+}
+
+// validUTF8 is utf8.Valid, forgiving a rune cut off at the end of the sample.
+func validUTF8(b []byte) bool {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if !utf8.FullRune(b[i:]) {
+				b = b[:i]
+			}
+			break
+		}
+	}
+	return utf8.Valid(b)
+}
+
+// detectUTF32 looks for text where three bytes of every four are zero.
+func detectUTF32(b []byte) (EncodingHint, bool) {
+	quads := len(b) / 4
+	if quads < 2 {
+		return UTF8, false
+	}
+	var zero [4]int
+	for i := 0; i+3 < len(b); i += 4 {
+		for j := 0; j < 4; j++ {
+			if b[i+j] == 0 {
+				zero[j]++
+			}
+		}
+	}
+	switch {
+	case zero[2] == quads && zero[3] == quads && zero[0]*20 < quads:
+		return UTF32LE, true
+	case zero[0] == quads && zero[1] == quads && zero[3]*20 < quads:
+		return UTF32BE, true
+	}
+	return UTF8, false
+}
+
+// detectUTF16 looks for text where every other byte is zero.
+func detectUTF16(b []byte) (EncodingHint, bool) {
+	pairs := len(b) / 2
+	if pairs < 2 {
+		return UTF8, false
+	}
+	even, odd := 0, 0
+	for i := 0; i+1 < len(b); i += 2 {
+		if b[i] == 0 {
+			even++
+		}
+		if b[i+1] == 0 {
+			odd++
+		}
+	}
+	switch {
+	case odd*10 > pairs*3 && even*20 < pairs:
+		return UTF16LE, true
+	case even*10 > pairs*3 && odd*20 < pairs:
+		return UTF16BE, true
+	}
+	return UTF8, false
+}
+
+// Frequent characters of each language. A correct decoding produces many of them.
+var (
+	frequentJapanese = "のにはをたがでてとしれさいるもなかこんっすあうよ日本人一大年中"
+	frequentChinese  = "的一是不了人我在有他这這中大来來上个個国國到说說们們为為子和你地出道也时時年"
+	frequentKorean   = "이의다는에하고을를가한서지로기사으대정도어수리자나"
+)
+
+// detectCJK scores the decodings of the multibyte encodings.
+func detectCJK(b []byte) (EncodingHint, bool) {
+	high, paired := 0, 0
+	for i, c := range b {
+		if c < 0x80 {
+			continue
+		}
+		high++
+		if (i > 0 && b[i-1] >= 0x80) || (i+1 < len(b) && b[i+1] >= 0x80) {
+			paired++
+		}
+	}
+	if high == 0 || paired*2 < high { // isolated high bytes: a single byte encoding
+		return UTF8, false
+	}
+	// stop at the last ASCII byte, so no character is cut off.
+	if i := bytes.LastIndexFunc(b, func(r rune) bool { return r < 0x80 }); i > 0 {
+		b = b[:i+1]
+	}
+	best, bestScore := UTF8, 0
+	for _, d := range []EncodingHint{SHIFTJIS, EUCJP, GBK, BIG5, EUCKR} {
+		text, _, err := transform.Bytes(d.encoding().NewDecoder(), b)
+		if err != nil {
+			continue
+		}
+		frequent := frequentChinese
+		switch d {
+		case SHIFTJIS, EUCJP:
+			frequent = frequentJapanese
+		case EUCKR:
+			frequent = frequentKorean
+		}
+		score := 0
+		for _, r := range string(text) {
+			switch {
+			case r == utf8.RuneError:
+				score -= 10
+			case r < 0x80:
+			case strings.ContainsRune(frequent, r):
+				score += 3
+			case isScript(d, r):
+				score++
+			default:
+				score--
+			}
+		}
+		if score > bestScore {
+			best, bestScore = d, score
+		}
+	}
+	return best, bestScore > 0
+}
+
+// isScript reports whether r is a letter the language of d is written in.
+func isScript(d EncodingHint, r rune) bool {
+	kana := r >= 0x3040 && r <= 0x30ff
+	han := r >= 0x4e00 && r <= 0x9fff
+	hangul := r >= 0xac00 && r <= 0xd7af
+	punctuation := (r >= 0x3000 && r <= 0x303f) || (r >= 0xff01 && r <= 0xff5e)
+	switch d {
+	case SHIFTJIS, EUCJP:
+		return kana || han || punctuation
+	case EUCKR:
+		return hangul || punctuation
+	}
+	return han || punctuation
+}
+
+// detectLatin - bytes 0x80 thru 0x9F are printable only in Windows-1252.
+func detectLatin(b []byte) EncodingHint {
+	for _, c := range b {
+		if c >= 0x80 && c <= 0x9f {
+			return CP1252
+		}
+	}
+	return ISO8859_1
+}
+
+// WriteOptions controls how text is written.
+type WriteOptions struct {
+	BOM     bool // start with a byte order mark (UTF encodings only)
+	CRLF    bool // end lines with CR LF
+	Replace bool // replace characters the encoding lacks, instead of failing
+}
+
+// writeCloser flushes the encoder, then closes the file.
+type writeCloser struct {
+	writer io.WriteCloser
+	file   *os.File
+}
+
+// Write implements the standard Writer interface.
+func (w writeCloser) Write(p []byte) (n int, err error) {
+	return w.writer.Write(p)
+}
+
+// Close implements the standard Closer interface.
+func (w writeCloser) Close() error {
+	err := w.writer.Close()
+	if e := w.file.Close(); err == nil {
+		err = e
+	}
+	return err
+}
+
+// NewWriter wraps a Writer to encode UTF-8 as it writes.
+// Close flushes the encoder; it does not close w.
+func NewWriter(w io.Writer, d EncodingHint, opts WriteOptions) io.WriteCloser {
+	if d == AUTO {
+		d = UTF8
+	}
+	encoder := d.encoding().NewEncoder()
+	if opts.Replace {
+		encoder = encoding.ReplaceUnsupported(encoder)
+	}
+	var t transform.Transformer = encoder
+	if opts.CRLF {
+		t = transform.Chain(&crlf{}, encoder)
+	}
+	tw := transform.NewWriter(w, t)
+	if opts.BOM && d.isUnicode() {
+		_, _ = tw.Write([]byte("\ufeff"))
+	}
+	return tw
+}
+
+// CreateFile is the equivalent of os.Create().
+//goland:noinspection GoUnusedExportedFunction
+func CreateFile(name string, d EncodingHint, opts WriteOptions) (io.WriteCloser, error) {
+	f, err := os.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	return writeCloser{writer: NewWriter(f, d, opts), file: f}, nil
+}
+
+// WriteFile is the equivalent of ioutil.WriteFile()
+//goland:noinspection GoUnusedExportedFunction
+func WriteFile(name string, data []byte, d EncodingHint, opts WriteOptions) error {
+	file, err := CreateFile(name, d, opts)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if e := file.Close(); err == nil {
+		err = e
+	}
+	return err
+}
+
+// crlf is a transformer that ends every line with CR LF.
+type crlf struct {
+	cr bool // last byte was CR
+}
+
+// Reset implements transform.Transformer.
+func (c *crlf) Reset() {
+	c.cr = false
+}
+
+// Transform implements transform.Transformer.
+func (c *crlf) Transform(dst, src []byte, _ bool) (nDst, nSrc int, err error) {
+	for ; nSrc < len(src); nSrc++ {
+		b := src[nSrc]
+		if b == '\n' && !c.cr {
+			if nDst+2 > len(dst) {
+				return nDst, nSrc, transform.ErrShortDst
+			}
+			dst[nDst] = '\r'
+			nDst++
+		} else if nDst+1 > len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		dst[nDst] = b
+		nDst++
+		c.cr = b == '\r'
+	}
+	return nDst, nSrc, nil
+}
@@ -0,0 +1,188 @@
+package misc
+
+import (
+	"bytes"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/encoding/unicode/utf32"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+/*
+
+  File:    utfutil_test.go
+  Author:  Bob Shofner
+
+*/
+/*
+  Description: detect and decode each encoding.
+*/
+
+const (
+	textEnglish  = "The quick brown fox jumps over the lazy dog.\n"
+	textFrench   = "Le cœur déçu mais l’âme plutôt naïve, Louÿs rêva de crapaüter.\n"
+	textLatin    = "Ça m'a coûté très cher, señor.\n"
+	textJapanese = "こんにちは、世界。これは日本語のテキストです。私たちはここにいます。\n"
+	textChinese  = "这是一个中文的测试文本，我们在这里说话。中国的人口很多。\n"
+	textTaiwan   = "這是一個中文的測試文本，我們在這裡說話。中國的人口很多。\n"
+	textKorean   = "안녕하세요. 이것은 한국어 테스트입니다. 우리는 여기에 있습니다.\n"
+)
+
+func TestDetectEncoding(t *testing.T) {
+	var tests = []struct {
+		name string
+		text string
+		enc  encoding.Encoding
+		want EncodingHint
+		bom  bool
+	}{
+		{"ASCII", textEnglish, nil, UTF8, false},
+		{"UTF-8", textFrench, nil, UTF8, false},
+		{"UTF-8 BOM", textFrench, unicode.UTF8BOM, UTF8, true},
+		{"UTF-16LE", textEnglish, UTF16LE.encoding(), UTF16LE, false},
+		{"UTF-16BE", textFrench, UTF16BE.encoding(), UTF16BE, false},
+		{"UTF-16LE BOM", textJapanese, unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), UTF16LE, true},
+		{"windows-1252", textFrench, CP1252.encoding(), CP1252, false},
+		{"ISO-8859-1", textLatin, ISO8859_1.encoding(), ISO8859_1, false},
+		{"Shift_JIS", textJapanese, SHIFTJIS.encoding(), SHIFTJIS, false},
+		{"EUC-JP", textJapanese, EUCJP.encoding(), EUCJP, false},
+		{"GBK", textChinese, GBK.encoding(), GBK, false},
+		{"Big5", textTaiwan, BIG5.encoding(), BIG5, false},
+		{"EUC-KR", textKorean, EUCKR.encoding(), EUCKR, false},
+		{"UTF-32LE", textFrench, UTF32LE.encoding(), UTF32LE, false},
+		{"UTF-32BE BOM", textJapanese, utf32.UTF32(utf32.BigEndian, utf32.UseBOM), UTF32BE, true},
+		{"UTF-32LE BOM", textEnglish, utf32.UTF32(utf32.LittleEndian, utf32.UseBOM), UTF32LE, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := []byte(strings.Repeat(tt.text, 3))
+			if tt.enc != nil {
+				var err error
+				if data, err = tt.enc.NewEncoder().Bytes(data); err != nil {
+					t.Fatalf("encode: %v", err)
+				}
+			}
+			d, bom := DetectEncoding(data)
+			if d != tt.want || bom != tt.bom {
+				t.Errorf("Expected %s (BOM %t): got %s (BOM %t)", tt.want, tt.bom, d, bom)
+			}
+			r, d := NewReaderDetect(bytes.NewReader(data), AUTO)
+			if d != tt.want {
+				t.Errorf("Reader expected %s: got %s", tt.want, d)
+			}
+			b, _ := ioutil.ReadAll(r)
+			if string(b) != strings.Repeat(tt.text, 3) {
+				t.Errorf("Expected %q: got %q", tt.text, string(b))
+			}
+		})
+	}
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	var tests = []struct {
+		name string
+		text string
+		hint EncodingHint
+		opts WriteOptions
+	}{
+		{"UTF-8 BOM CRLF", textFrench, UTF8, WriteOptions{BOM: true, CRLF: true}},
+		{"UTF-16LE BOM", textJapanese, UTF16LE, WriteOptions{BOM: true}},
+		{"UTF-32BE", textKorean, UTF32BE, WriteOptions{}},
+		{"UTF-32LE BOM", textFrench, UTF32LE, WriteOptions{BOM: true}},
+		{"windows-1252 CRLF", textFrench, CP1252, WriteOptions{CRLF: true}},
+		{"IBM437", "Café crème, 25°C\n", IBM437, WriteOptions{}},
+		{"macintosh", "Café crème — “quoted”\n", MACROMAN, WriteOptions{}},
+		{"ISO-8859-2", "Zażółć gęślą jaźń\n", ISO8859_2, WriteOptions{}},
+		{"ISO-8859-5", "Съешь же ещё этих мягких булок\n", ISO8859_5, WriteOptions{}},
+		{"ISO-8859-7", "Ξεσκεπάζω την ψυχοφθόρα βδελυγμία\n", ISO8859_7, WriteOptions{}},
+		{"ISO-8859-15", "Prix: 20€\n", ISO8859_15, WriteOptions{}},
+	}
+	dir := t.TempDir()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := filepath.Join(dir, tt.hint.String())
+			text := strings.Repeat(tt.text, 3)
+			if err := WriteFile(name, []byte(text), tt.hint, tt.opts); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+			raw, _ := ioutil.ReadFile(name)
+			if _, bom := DetectEncoding(raw); bom != tt.opts.BOM {
+				t.Errorf("BOM %t: want %t", bom, tt.opts.BOM)
+			}
+			b, err := ReadFile(name, tt.hint)
+			if err != nil {
+				t.Fatalf("ReadFile: %v", err)
+			}
+			want := text
+			if tt.opts.CRLF {
+				want = strings.ReplaceAll(text, "\n", "\r\n")
+			}
+			if string(b) != want {
+				t.Errorf("Expected %q: got %q", want, string(b))
+			}
+		})
+	}
+}
+
+func TestWriteUnsupported(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, ISO8859_1, WriteOptions{})
+	_, err := w.Write([]byte("20€"))
+	if err == nil {
+		err = w.Close()
+	}
+	if err == nil {
+		t.Error("Expected an error for € in ISO-8859-1")
+	}
+	buf.Reset()
+	w = NewWriter(&buf, ISO8859_1, WriteOptions{Replace: true, CRLF: true})
+	_, _ = w.Write([]byte("20€\r\nok\n"))
+	_ = w.Close()
+	if got := buf.String(); got != "20\x1a\r\nok\r\n" {
+		t.Errorf("Expected replacement and CRLF: got %q", got)
+	}
+}
+
+func TestNewReaderPipe(t *testing.T) {
+	pr, pw := io.Pipe()
+	made := make(chan UTFReadCloser)
+	go func() { made <- NewReader(pr, UTF16LE) }()
+	var r UTFReadCloser
+	select {
+	case r = <-made:
+	case <-time.After(2 * time.Second):
+		t.Fatal("NewReader waited for data")
+	}
+	go func() {
+		_, _ = pw.Write([]byte{'o', 0, 'k', 0})
+		_ = pw.Close()
+	}()
+	if b, _ := ioutil.ReadAll(r); string(b) != "ok" {
+		t.Errorf("Expected ok: got %q", b)
+	}
+}
+
+func TestLegacyIgnoresBOM(t *testing.T) {
+	var tests = []struct {
+		data []byte
+		hint EncodingHint
+		want string
+	}{
+		{[]byte("\xff\xfeA"), CP1252, "ÿþA"},
+		{[]byte("\xfe\xffA"), ISO8859_1, "þÿA"},
+		{[]byte("\xff\xfeA\x00"), UTF8, "A"}, // a UTF hint abides by the BOM
+	}
+	for _, tt := range tests {
+		t.Run(tt.hint.String(), func(t *testing.T) {
+			b, _ := ioutil.ReadAll(BytesReader(tt.data, tt.hint))
+			if string(b) != tt.want {
+				t.Errorf("Expected %q: got %q", tt.want, b)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package misc
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+/*
+
+  File:    volume.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: The mounted volumes (drives, USB sticks ...) of the system.
+
+  On linux Volumes reads /proc/self/mountinfo, one mount per line:
+    36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue
+    id parent major:minor root mount-point options [optional ...] - type source super-options
+  Spaces (and tabs, new lines, backslashes) in the paths are octal escapes ("\040").
+  Pseudo filesystems (proc, sysfs, cgroup, tmpfs, squashfs snaps ...) and
+    the mounts below /proc, /sys, /dev and /run (other than /run/media)
+    are not volumes.
+*/
+
+// ErrNoVolumes is returned by Volumes where they are not known.
+var ErrNoVolumes = errors.New("volumes are only known on linux")
+
+// DiskUsage is the size of a volume, in bytes.
+type DiskUsage struct {
+	All   uint64
+	Used  uint64
+	Free  uint64
+	Avail uint64 // free to an unprivileged user
+}
+
+// Volume is a mounted filesystem.
+type Volume struct {
+	MountPoint string
+	Device     string // "/dev/sda1"
+	FSType     string // "ext4", "vfat" ...
+	Label      string // from /dev/disk/by-label
+	Root       string // the directory of the filesystem mounted ("/" unless a bind mount or a subvolume)
+	ReadOnly   bool
+	Removable  bool // a USB stick, SD card, CD ...
+	Usage      DiskUsage
+}
+
+// pseudoFS are the filesystem types that are not volumes.
+var pseudoFS = map[string]bool{
+	"autofs": true, "binfmt_misc": true, "bpf": true, "cgroup": true, "cgroup2": true,
+	"configfs": true, "debugfs": true, "devpts": true, "devtmpfs": true, "efivarfs": true,
+	"fusectl": true, "hugetlbfs": true, "mqueue": true, "nsfs": true, "proc": true,
+	"pstore": true, "ramfs": true, "rpc_pipefs": true, "securityfs": true, "selinuxfs": true,
+	"squashfs": true, "sysfs": true, "tmpfs": true, "tracefs": true,
+	"fuse.gvfsd-fuse": true, "fuse.portal": true, "fuse.lxcfs": true,
+}
+
+// systemDirs are where only pseudo mounts live.
+var systemDirs = []string{"/proc", "/sys", "/dev", "/run"}
+
+// IsPseudoFS reports whether the filesystem type fsType is not a volume (proc, tmpfs ...).
+//goland:noinspection GoUnusedExportedFunction
+func IsPseudoFS(fsType string) bool {
+	return pseudoFS[fsType]
+}
+
+// parseMountinfo reads the volumes of a mountinfo file, pseudo filesystems left out.
+// A later mount on the same mount point hides the one before.
+func parseMountinfo(r io.Reader) ([]Volume, error) {
+	volumes := make([]Volume, 0)
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		sep := -1
+		for i := 6; i < len(fields); i++ {
+			if fields[i] == "-" {
+				sep = i
+				break
+			}
+		}
+		if sep < 0 || sep+2 >= len(fields) {
+			return volumes, errors.New(fmt.Sprintf("mountinfo line %d: %s", n, scanner.Text()))
+		}
+		v := Volume{
+			Root:       unescapeMount(fields[3]),
+			MountPoint: unescapeMount(fields[4]),
+			FSType:     fields[sep+1],
+			Device:     unescapeMount(fields[sep+2]),
+		}
+		for _, o := range strings.Split(fields[5], ",") {
+			if o == "ro" {
+				v.ReadOnly = true
+			}
+		}
+		if pseudoFS[v.FSType] || isSystemMount(v.MountPoint) {
+			continue
+		}
+		volumes = append(volumes, v)
+	}
+	if err := scanner.Err(); err != nil {
+		return volumes, err
+	}
+	// keep the last mount of each mount point
+	seen := make(map[string]bool)
+	kept := make([]Volume, 0, len(volumes))
+	for i := len(volumes) - 1; i >= 0; i-- {
+		if !seen[volumes[i].MountPoint] {
+			seen[volumes[i].MountPoint] = true
+			kept = append(kept, volumes[i])
+		}
+	}
+	for i, j := 0, len(kept)-1; i < j; i, j = i+1, j-1 {
+		kept[i], kept[j] = kept[j], kept[i]
+	}
+	return kept, nil
+}
+
+// isSystemMount reports whether dir is in /proc, /sys, /dev or /run (but not /run/media).
+func isSystemMount(dir string) bool {
+	if dir == "/run/media" || strings.HasPrefix(dir, "/run/media/") {
+		return false
+	}
+	for _, sys := range systemDirs {
+		if dir == sys || strings.HasPrefix(dir, sys+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// unescapeMount decodes the octal escapes ("\040" is a space) of a mountinfo path.
+func unescapeMount(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) {
+			if c, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b = append(b, byte(c))
+				i += 3
+				continue
+			}
+		}
+		b = append(b, s[i])
+	}
+	return string(b)
+}
+
+// unescapeLabel decodes the hex escapes of udev ("My\x20Stick").
+func unescapeLabel(s string) string {
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) && s[i+1] == 'x' {
+			if c, err := strconv.ParseUint(s[i+2:i+4], 16, 8); err == nil {
+				b = append(b, byte(c))
+				i += 3
+				continue
+			}
+		}
+		b = append(b, s[i])
+	}
+	return string(b)
+}
@@ -0,0 +1,104 @@
+//go:build linux
+// +build linux
+
+package misc
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+/*
+
+  File:    volume_linux.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: The mounted volumes of linux.
+
+  A partition (/dev/sdb1) is removable if its disk (/sys/block/sdb) says
+    so, or is on a USB bus.
+*/
+
+// Volumes are the mounted volumes, in mount order ("/" first).
+//goland:noinspection GoUnusedExportedFunction
+func Volumes() ([]Volume, error) {
+	f, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	mounts, err := parseMountinfo(f)
+	if err != nil {
+		return nil, err
+	}
+	labels := diskLabels()
+	volumes := make([]Volume, 0, len(mounts))
+	for _, v := range mounts {
+		if info, err := os.Stat(v.MountPoint); err != nil || !info.IsDir() {
+			continue // a file bind mounted (/etc/hosts), or not reachable
+		}
+		if strings.HasPrefix(v.Device, "/dev/") {
+			if dev, err := filepath.EvalSymlinks(v.Device); err == nil {
+				v.Device = dev
+			}
+			v.Label = labels[v.Device]
+			v.Removable = isRemovable(v.Device)
+		}
+		v.Usage = diskUsage(v.MountPoint)
+		volumes = append(volumes, v)
+	}
+	return volumes, nil
+}
+
+// diskUsage is the size of the volume holding path.
+func diskUsage(path string) (disk DiskUsage) {
+	fs := syscall.Statfs_t{}
+	if syscall.Statfs(path, &fs) == nil {
+		disk.All = fs.Blocks * uint64(fs.Bsize)
+		disk.Avail = fs.Bavail * uint64(fs.Bsize)
+		disk.Free = fs.Bfree * uint64(fs.Bsize)
+		disk.Used = disk.All - disk.Free
+	}
+	return
+}
+
+// diskLabels are the labels of the devices, from the links in /dev/disk/by-label.
+func diskLabels() map[string]string {
+	labels := make(map[string]string)
+	const dir = "/dev/disk/by-label"
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return labels
+	}
+	for _, e := range entries {
+		if dev, err := filepath.EvalSymlinks(filepath.Join(dir, e.Name())); err == nil {
+			labels[dev] = unescapeLabel(e.Name())
+		}
+	}
+	return labels
+}
+
+// isRemovable reports whether the disk of dev ("/dev/sdb1") is removable or on USB.
+func isRemovable(dev string) bool {
+	sys, err := filepath.EvalSymlinks(filepath.Join("/sys/class/block", filepath.Base(dev)))
+	if err != nil {
+		return false
+	}
+	if _, err = os.Stat(filepath.Join(sys, "partition")); err == nil {
+		sys = filepath.Dir(sys) // the disk of the partition
+	}
+	if b, err := os.ReadFile(filepath.Join(sys, "removable")); err == nil && strings.TrimSpace(string(b)) == "1" {
+		return true
+	}
+	return strings.Contains(sys, "/usb")
+}
@@ -0,0 +1,26 @@
+//go:build !linux
+// +build !linux
+
+package misc
+
+/*
+
+  File:    volume_other.go
+  Author:  Bob Shofner
+
+  Copyright (c) 2022. BSD 3-Clause License
+	https://opensource.org/licenses/BSD-3-Clause
+
+  The this permission notice shall be included in all copies
+    or substantial portions of the Software.
+
+*/
+/*
+  Description: The mounted volumes of systems other than linux (not yet known).
+*/
+
+// Volumes are the mounted volumes. ErrNoVolumes except on linux.
+//goland:noinspection GoUnusedExportedFunction
+func Volumes() ([]Volume, error) {
+	return nil, ErrNoVolumes
+}
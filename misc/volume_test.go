@@ -0,0 +1,67 @@
+package misc
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+/*
+
+  File:    volume_test.go
+  Author:  Bob Shofner
+
+*/
+
+const mountinfo = `22 1 8:2 / / rw,relatime shared:1 - ext4 /dev/sda2 rw,errors=remount-ro
+23 22 0:21 / /proc rw,nosuid,nodev,noexec,relatime shared:12 - proc proc rw
+24 22 0:22 / /sys rw,nosuid,nodev,noexec,relatime shared:7 - sysfs sysfs rw
+25 22 0:5 / /dev rw,nosuid,relatime shared:2 - devtmpfs udev rw,size=8110160k,mode=755
+26 24 0:25 / /sys/fs/cgroup ro,nosuid,nodev,noexec shared:9 - cgroup2 cgroup2 rw
+27 22 0:26 / /run rw,nosuid,nodev,noexec,relatime shared:5 - tmpfs tmpfs rw,size=1631812k
+28 22 7:1 / /snap/core/123 ro,nodev,relatime shared:30 - squashfs /dev/loop1 ro
+29 22 8:1 / /boot/efi rw,relatime shared:31 - vfat /dev/sda1 rw,fmask=0077
+30 22 8:3 /@home /home rw,relatime shared:32 - btrfs /dev/sda3 rw,space_cache
+31 27 8:17 / /run/media/bob/My\040Stick rw,nosuid,nodev,relatime shared:40 - vfat /dev/sdb1 rw
+32 22 0:40 / /mnt/cd ro,relatime shared:41 master:7 - iso9660 /dev/sr0 ro
+33 22 8:4 / /home rw,relatime shared:33 - ext4 /dev/sda4 rw
+`
+
+func TestParseMountinfo(t *testing.T) {
+	volumes, err := parseMountinfo(strings.NewReader(mountinfo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]string, 0, len(volumes))
+	for _, v := range volumes {
+		got = append(got, fmt.Sprintf("%s %s %s %v", v.MountPoint, v.Device, v.FSType, v.ReadOnly))
+	}
+	want := "[/ /dev/sda2 ext4 false|/boot/efi /dev/sda1 vfat false|" +
+		"/run/media/bob/My Stick /dev/sdb1 vfat false|/mnt/cd /dev/sr0 iso9660 true|/home /dev/sda4 ext4 false]"
+	if s := "[" + strings.Join(got, "|") + "]"; s != want {
+		t.Errorf("volumes\n%s\nwant\n%s", s, want)
+	}
+	if _, err = parseMountinfo(strings.NewReader("22 1 8:2 / / rw\n")); err == nil {
+		t.Error("short line: want an error")
+	}
+	if IsPseudoFS("ext4") || !IsPseudoFS("tmpfs") {
+		t.Error("IsPseudoFS")
+	}
+	if s := unescapeLabel(`My\x20Stick`); s != "My Stick" {
+		t.Errorf("unescapeLabel = %q", s)
+	}
+}
+
+func TestVolumes(t *testing.T) {
+	volumes, err := Volumes()
+	if err == ErrNoVolumes {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range volumes {
+		t.Logf("%s %s %s %q removable %v %s bytes", v.MountPoint, v.Device, v.FSType, v.Label, v.Removable,
+			PrettyUint(v.Usage.All, ","))
+	}
+}